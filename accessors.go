@@ -0,0 +1,53 @@
+package arc69
+
+// The descriptive fields of ARC69 metadata are all optional. Since a field that
+// is absent from a note decodes to the empty string, the accessors below treat an
+// empty field as absent.
+
+// HasDescription reports whether m has a description.
+func (m *Metadata) HasDescription() bool {
+	return m.Description != ""
+}
+
+// DescriptionOr returns the description of m, or def if it has none.
+func (m *Metadata) DescriptionOr(def string) string {
+	return stringOr(m.Description, def)
+}
+
+// HasExternalURL reports whether m has an external URL.
+func (m *Metadata) HasExternalURL() bool {
+	return m.ExternalURL != ""
+}
+
+// ExternalURLOr returns the external URL of m, or def if it has none.
+func (m *Metadata) ExternalURLOr(def string) string {
+	return stringOr(m.ExternalURL, def)
+}
+
+// HasMediaURL reports whether m has a media URL.
+func (m *Metadata) HasMediaURL() bool {
+	return m.MediaURL != ""
+}
+
+// MediaURLOr returns the media URL of m, or def if it has none.
+func (m *Metadata) MediaURLOr(def string) string {
+	return stringOr(m.MediaURL, def)
+}
+
+// HasMimeType reports whether m has a MIME type.
+func (m *Metadata) HasMimeType() bool {
+	return m.MimeType != ""
+}
+
+// MimeTypeOr returns the MIME type of m, or def if it has none.
+func (m *Metadata) MimeTypeOr(def string) string {
+	return stringOr(m.MimeType, def)
+}
+
+// Helper function that returns v, or def if v is empty.
+func stringOr(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
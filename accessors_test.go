@@ -0,0 +1,39 @@
+package arc69
+
+import "testing"
+
+func TestMetadataAccessors(t *testing.T) {
+	present := &Metadata{
+		Description: "desc",
+		ExternalURL: "https://example.com",
+		MediaURL:    "ipfs://media",
+		MimeType:    "image/png",
+	}
+	empty := &Metadata{}
+
+	tests := []struct {
+		name         string
+		has          func(*Metadata) bool
+		or           func(*Metadata, string) string
+		presentValue string
+	}{
+		{"Description", (*Metadata).HasDescription, (*Metadata).DescriptionOr, "desc"},
+		{"ExternalURL", (*Metadata).HasExternalURL, (*Metadata).ExternalURLOr, "https://example.com"},
+		{"MediaURL", (*Metadata).HasMediaURL, (*Metadata).MediaURLOr, "ipfs://media"},
+		{"MimeType", (*Metadata).HasMimeType, (*Metadata).MimeTypeOr, "image/png"},
+	}
+	for _, test := range tests {
+		if !test.has(present) {
+			t.Errorf("Has%s() = false on set field, want true", test.name)
+		}
+		if got := test.or(present, "default"); got != test.presentValue {
+			t.Errorf("%sOr() = %q on set field, want %q", test.name, got, test.presentValue)
+		}
+		if test.has(empty) {
+			t.Errorf("Has%s() = true on empty field, want false", test.name)
+		}
+		if got := test.or(empty, "default"); got != "default" {
+			t.Errorf("%sOr() = %q on empty field, want \"default\"", test.name, got)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package arc69
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// FromARC3 converts an ARC3 metadata document into ARC69 metadata. The ARC3
+// description, external_url, properties and attributes fields are carried over,
+// image becomes the media URL and image_mimetype (or mime_type) the MIME type.
+// ARC69 has no name field, so name is kept as the "name" property, which is where
+// CheckConsistency looks for it, unless the properties already hold one. The names
+// of the fields that are not carried over, such as decimals, are returned in
+// sorted order so callers can decide what to do with them.
+func FromARC3(arc3 []byte) (*Metadata, []string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(arc3, &fields); err != nil {
+		return nil, nil, fmt.Errorf("unable to parse ARC3 metadata: %s", err)
+	}
+
+	meta := &Metadata{Standard: "arc69"}
+	var unmapped []string
+	var name interface{}
+	for key, value := range fields {
+		var target interface{}
+		switch key {
+		case "name":
+			target = &name
+		case "description":
+			target = &meta.Description
+		case "external_url":
+			target = &meta.ExternalURL
+		case "image":
+			target = &meta.MediaURL
+		case "image_mimetype", "mime_type":
+			target = &meta.MimeType
+		case "properties":
+			target = &meta.Properties
+		case "attributes":
+			target = &meta.Attributes
+		default:
+			unmapped = append(unmapped, key)
+			continue
+		}
+
+		if err := newNumberDecoder(bytes.NewReader(value)).Decode(target); err != nil {
+			return nil, nil, fmt.Errorf("unable to convert ARC3 field %s: %s", key, err)
+		}
+	}
+	if _, ok := fields["name"]; ok {
+		if _, exists := meta.Properties["name"]; exists {
+			unmapped = append(unmapped, "name")
+		} else {
+			if meta.Properties == nil {
+				meta.Properties = make(map[string]interface{})
+			}
+			meta.Properties["name"] = name
+		}
+	}
+	sort.Strings(unmapped)
+
+	return meta, unmapped, nil
+}
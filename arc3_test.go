@@ -0,0 +1,59 @@
+package arc69
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFromARC3(t *testing.T) {
+	arc3 := []byte(`{
+		"name": "Creature #42",
+		"decimals": 0,
+		"description": "A creature from the deep.",
+		"image": "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		"image_integrity": "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		"image_mimetype": "image/png",
+		"external_url": "https://creatures.example",
+		"properties": {"rarity": "legendary", "stats": {"power": 9}}
+	}`)
+
+	got, unmapped, err := FromARC3(arc3)
+	if err != nil {
+		t.Fatalf("FromARC3() failed with error: %s, want success", err)
+	}
+
+	want := &Metadata{
+		Standard:    "arc69",
+		Description: "A creature from the deep.",
+		ExternalURL: "https://creatures.example",
+		MediaURL:    "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		MimeType:    "image/png",
+		Properties: map[string]interface{}{
+			"name":   "Creature #42",
+			"rarity": "legendary",
+			"stats":  map[string]interface{}{"power": json.Number("9")},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromARC3() = %+v, want %+v", got, want)
+	}
+	if wantUnmapped := []string{"decimals", "image_integrity"}; !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("FromARC3() unmapped = %q, want %q", unmapped, wantUnmapped)
+	}
+
+	got, unmapped, err = FromARC3([]byte(`{"name":"Creature #42","properties":{"name":"Kraken"}}`))
+	if err != nil {
+		t.Fatalf("FromARC3() failed with error: %s, want success", err)
+	}
+	if name := got.Properties["name"]; name != "Kraken" {
+		t.Errorf("FromARC3() with a name property kept name %q, want \"Kraken\"", name)
+	}
+	if wantUnmapped := []string{"name"}; !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("FromARC3() with a name property unmapped = %q, want %q", unmapped, wantUnmapped)
+	}
+
+	if _, _, err := FromARC3([]byte("not json")); err == nil {
+		t.Errorf("FromARC3() of invalid JSON succeeded, want error")
+	}
+}
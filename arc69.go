@@ -2,25 +2,59 @@
 package arc69
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
+	"math/big"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/algorand/go-algorand-sdk/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
 	"github.com/algorand/go-algorand-sdk/crypto"
 	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
 )
 
+// maxValidityWindow is the maximum number of rounds a transaction can be valid for.
+const maxValidityWindow = 1000
+
+// ErrMissingClient is returned by network methods called on an ARC69 object that
+// lacks the algod or indexer client they need.
+var ErrMissingClient = errors.New("client is missing")
+
+// ErrAssetImmutable is returned when updating the metadata of an asset whose
+// manager address has been cleared, which makes its configuration immutable.
+var ErrAssetImmutable = errors.New("asset is immutable")
+
+// ErrConflict is returned by UpdateIfUnchanged when the asset was reconfigured
+// after the round the caller last saw.
+var ErrConflict = errors.New("asset was updated concurrently")
+
+// ErrClosed is returned by network methods called on an ARC69 object after Close.
+var ErrClosed = errors.New("arc69 is closed")
+
 // ARC69 is the interface through which users can interact with ARC69-compliant ASA metadata.
+// An ARC69 is safe for concurrent use by multiple goroutines: its configuration is
+// fixed by New and its internal caches guard themselves with their own locks.
 type ARC69 struct {
-	algodClient   *algod.Client
-	indexerClient *indexer.Client
+	options options
+	cache   *fetchCache
+
+	// mu guards closed and the registration of watchers.
+	mu       sync.Mutex
+	closed   bool
+	done     chan struct{}
+	watchers sync.WaitGroup
 }
 
 // Metadata holds ARC69-compliant ASA metadata as described at https://github.com/algokittens/arc69.
@@ -32,120 +66,1015 @@ type Metadata struct {
 	Properties  map[string]interface{} `json:"properties"`
 	MimeType    string                 `json:"mime_type"`
 	Attributes  []Attribute            `json:"attributes"`
+
+	// arrayProperties is set when the properties were parsed from a legacy JSON
+	// array, so that they are encoded back as one.
+	arrayProperties bool
+}
+
+// MarshalJSON implements json.Marshaler. Properties parsed from a legacy JSON
+// array are encoded back as an array as long as their keys are still the indexes
+// "0" to "n-1"; otherwise they are encoded as an object.
+func (m Metadata) MarshalJSON() ([]byte, error) {
+	type metadata Metadata
+	props, ok := m.propertiesArray()
+	if !ok {
+		return json.Marshal(metadata(m))
+	}
+	return json.Marshal(struct {
+		Standard    string        `json:"standard"`
+		Description string        `json:"description"`
+		ExternalURL string        `json:"external_url"`
+		MediaURL    string        `json:"media_url"`
+		Properties  []interface{} `json:"properties"`
+		MimeType    string        `json:"mime_type"`
+		Attributes  []Attribute   `json:"attributes"`
+	}{m.Standard, m.Description, m.ExternalURL, m.MediaURL, props, m.MimeType, m.Attributes})
+}
+
+// Helper function that returns the properties of m as the legacy array they were
+// parsed from, reporting false if they were not or if their keys are no longer
+// the indexes of an array.
+func (m *Metadata) propertiesArray() ([]interface{}, bool) {
+	if !m.arrayProperties {
+		return nil, false
+	}
+	props := make([]interface{}, len(m.Properties))
+	for i := range props {
+		v, ok := m.Properties[strconv.Itoa(i)]
+		if !ok {
+			return nil, false
+		}
+		props[i] = v
+	}
+	return props, true
+}
+
+// MetadataSummary holds the top-level descriptive fields of ARC69 metadata,
+// without its properties and attributes.
+type MetadataSummary struct {
+	Standard    string `json:"standard"`
+	Description string `json:"description"`
+	MediaURL    string `json:"media_url"`
+	MimeType    string `json:"mime_type"`
 }
 
-// Attribute is an attribute that is part of ARC69 metadata.
+// Attribute is an attribute that is part of ARC69 metadata. Value holds the value
+// as it is stored in JSON: a string, a json.Number, a bool or nil. Values decoded
+// from JSON objects or arrays are kept as their JSON text. ValueString returns the
+// value as text whatever its type. Value used to be a string, so code comparing it
+// to a string (ex. attr.Value == "Blue") should compare attr.ValueString()
+// instead. DisplayType is an optional hint for marketplaces on how to render the
+// value (ex. "number", "boost_number", "date").
 type Attribute struct {
-	TraitType string `json:"trait_type"`
-	Value     string `json:"Sad"`
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	DisplayType string      `json:"display_type,omitempty"`
 }
 
-// New returns a new ARC69 object.
-func New(algodClient *algod.Client, indexerClient *indexer.Client) *ARC69 {
-	return &ARC69{algodClient: algodClient, indexerClient: indexerClient}
+// attributeJSON is the JSON encoding of an Attribute, with the value left raw.
+type attributeJSON struct {
+	TraitType   string          `json:"trait_type"`
+	Value       json.RawMessage `json:"value"`
+	DisplayType string          `json:"display_type"`
 }
 
-// Fetch attempts to retrieve the ARC69 metadata for an asset. An error is returned
-// if no metadata is found or if there is an error while parsing the metadata.
-func (a *ARC69) Fetch(ctx context.Context, assetID uint64) (*Metadata, error) {
-	if a.indexerClient == nil {
-		return nil, fmt.Errorf("client is missing")
+// UnmarshalJSON implements json.Unmarshaler. Numbers are decoded as json.Number so
+// that numeric and boolean values are encoded back with the same JSON type.
+func (attr *Attribute) UnmarshalJSON(data []byte) error {
+	var raw attributeJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
 	}
 
-	resp, err := a.indexerClient.LookupAssetTransactions(assetID).TxType("acfg").Do(ctx)
+	attr.TraitType = raw.TraitType
+	attr.DisplayType = raw.DisplayType
+	attr.Value = nil
+	if len(raw.Value) == 0 {
+		return nil
+	}
+
+	var value interface{}
+	if err := newNumberDecoder(bytes.NewReader(raw.Value)).Decode(&value); err != nil {
+		return err
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw.Value); err != nil {
+			return err
+		}
+		attr.Value = compact.String()
+	default:
+		attr.Value = value
+	}
+	return nil
+}
+
+// ValueString returns the value of attr as text: strings are returned as is,
+// numbers and bools as their JSON text and a missing value as an empty string.
+func (attr Attribute) ValueString() string {
+	switch v := attr.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// Helper function that reports whether two attributes are the same, comparing
+// numeric values by value like Equal.
+func sameAttribute(a, b Attribute) bool {
+	return a.TraitType == b.TraitType && a.DisplayType == b.DisplayType &&
+		reflect.DeepEqual(normalizeNumbers(a.Value), normalizeNumbers(b.Value))
+}
+
+// New returns a new ARC69 object. The given options apply to all of its method calls.
+// Either client may be nil, in which case the methods needing it return
+// ErrMissingClient.
+func New(algodClient *algod.Client, indexerClient *indexer.Client, opts ...Option) *ARC69 {
+	a := &ARC69{
+		options: options{algodClient: algodClient, indexerClient: indexerClient},
+		done:    make(chan struct{}),
+	}
+	a.options = *a.config(opts)
+	if a.options.cacheSize > 0 {
+		a.cache = newFetchCache(a.options.cacheSize, a.options.cacheTTL)
+	}
+	return a
+}
+
+// NewOffline returns a new ARC69 object without any client, for callers that only
+// parse, validate or edit metadata.
+func NewOffline(opts ...Option) *ARC69 {
+	return New(nil, nil, opts...)
+}
+
+// Close stops the goroutines started by Watch, waiting for their channels to be
+// closed, and clears the Fetch cache. Network methods return ErrClosed once Close
+// has been called, and updates waiting for a confirmation stop at their next poll.
+// Close can be called several times and always returns nil.
+func (a *ARC69) Close() error {
+	a.mu.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.done)
+	}
+	a.mu.Unlock()
+
+	a.watchers.Wait()
+	if a.cache != nil {
+		a.cache.clear()
+	}
+	return nil
+}
+
+// Fetch attempts to retrieve the ARC69 metadata for an asset. The notes of its acfg
+// transactions are scanned from the newest to the oldest and the first one holding
+// valid ARC69 metadata is returned, skipping notes left by other tools. If none
+// does, the error for the latest note is returned. WithLatestOnly disables the
+// scan. Besides plain JSON, notes holding base64-encoded or gzip-compressed JSON
+// are detected and decoded before parsing. Numbers held in properties are decoded
+// as json.Number rather than float64, so that large integers keep their precision.
+// Metadata written by UpdateChunked can only be read back with FetchChunked.
+func (a *ARC69) Fetch(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, error) {
+	useCache := a.cache != nil && len(opts) == 0
+	var gen uint64
+	if useCache {
+		if meta, ok := a.cache.get(assetID); ok {
+			return meta, nil
+		}
+		gen = a.cache.generation()
+	}
+
+	meta, err := a.fetchUncached(ctx, assetID, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(resp.Transactions) == 0 {
+	if useCache {
+		a.cache.add(assetID, meta, gen)
+	}
+
+	return meta, nil
+}
+
+// FetchSummary attempts to retrieve the summary of the ARC69 metadata for an asset.
+// The properties and attributes of the metadata are skipped while decoding, which
+// makes it cheaper than Fetch when listing many assets. The summary is read from
+// the same note as Fetch.
+func (a *ARC69) FetchSummary(ctx context.Context, assetID uint64, opts ...Option) (*MetadataSummary, error) {
+	cfg := a.config(opts)
+	var summary MetadataSummary
+	_, err := a.selectNote(ctx, cfg, assetID, func(note []byte) error {
+		data, err := decodeNote(note, cfg)
+		if err != nil {
+			return err
+		}
+		summary = MetadataSummary{}
+		if err := json.Unmarshal(data, &summary); err != nil {
+			return fmt.Errorf("unable to parse metadata: %s", err)
+		}
+		if !strings.EqualFold(summary.Standard, "arc69") {
+			return fmt.Errorf("invalid metadata")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &summary, nil
+}
+
+// FetchRaw attempts to retrieve the note of the latest acfg transaction of an asset
+// that has one, exactly as it is stored on chain. An error is returned if no note
+// is found.
+func (a *ARC69) FetchRaw(ctx context.Context, assetID uint64, opts ...Option) ([]byte, error) {
+	notes, err := a.latestNotes(ctx, a.config(opts), assetID)
+	if err != nil {
+		return nil, err
+	}
+	return notes[0], nil
+}
+
+// latestNotes returns the non-empty notes of the acfg transactions of an asset,
+// from the newest to the oldest. The indexer returns transactions from the oldest,
+// so every page is read. An error is returned if there are no notes.
+func (a *ARC69) latestNotes(ctx context.Context, cfg *options, assetID uint64) ([][]byte, error) {
+	var trans []models.Transaction
+	err := a.iterateAcfg(ctx, cfg, assetID, func(txn models.Transaction) error {
+		trans = append(trans, txn)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(trans) == 0 {
 		return nil, fmt.Errorf("no ARC69 metadata found for asset %d", assetID)
 	}
 
-	trans := resp.Transactions
-	sort.Slice(trans, func(i, j int) bool {
+	sort.SliceStable(trans, func(i, j int) bool {
 		return trans[i].RoundTime > trans[j].RoundTime
 	})
 
+	var notes [][]byte
 	for _, tran := range trans {
-		if len(tran.Note) == 0 {
-			continue
+		if len(tran.Note) != 0 {
+			notes = append(notes, tran.Note)
 		}
+	}
 
-		var meta Metadata
-		if err := json.Unmarshal(tran.Note, &meta); err != nil {
-			return nil, fmt.Errorf("unable to parse metadata: %s", err)
-		}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("no ARC69 metadata found for asset %d", assetID)
+	}
+
+	return notes, nil
+}
 
-		return &meta, nil
+// FetchByTxID attempts to retrieve the ARC69 metadata stored in the note of the
+// acfg transaction with the given ID. An error is returned if the transaction is
+// not an acfg transaction or if its note does not hold valid ARC69 metadata.
+func (a *ARC69) FetchByTxID(ctx context.Context, txID string, opts ...Option) (*Metadata, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, ErrMissingClient
+	}
+
+	var resp models.TransactionResponse
+	err := observe(ctx, cfg, "indexer.LookupTransaction", func(ctx context.Context) (err error) {
+		resp, err = cfg.indexerClient.LookupTransaction(txID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch transaction %s: %s", txID, err)
 	}
 
-	return nil, fmt.Errorf("no ARC69 metadata found for asset %d", assetID)
+	if resp.Transaction.Type != "acfg" {
+		return nil, fmt.Errorf("transaction %s is not an acfg transaction", txID)
+	}
+
+	if len(resp.Transaction.Note) == 0 {
+		return nil, fmt.Errorf("no ARC69 metadata found in transaction %s", txID)
+	}
+
+	meta, err := parseNote(resp.Transaction.Note, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !meta.IsValid() {
+		return nil, fmt.Errorf("invalid metadata in transaction %s", txID)
+	}
+
+	return meta, nil
 }
 
-// Update attempts to update the given ARC69 metadata for the given asset and
-// returns any errors.
-func (a *ARC69) Update(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata) error {
-	if a.algodClient == nil || a.indexerClient == nil {
-		return fmt.Errorf("client is missing")
+// BuildUpdate builds the unsigned acfg transaction that updates the ARC69 metadata
+// of the given asset to meta when signed by sender. The asset's current manager,
+// reserve, freeze and clawback addresses are preserved.
+func (a *ARC69) BuildUpdate(ctx context.Context, sender string, assetID uint64, meta *Metadata, opts ...Option) (types.Transaction, error) {
+	return a.buildUpdate(ctx, a.config(opts), sender, assetID, meta)
+}
+
+func (a *ARC69) buildUpdate(ctx context.Context, cfg *options, sender string, assetID uint64, meta *Metadata) (types.Transaction, error) {
+	if cfg.algodClient == nil || cfg.indexerClient == nil {
+		return types.Transaction{}, ErrMissingClient
 	}
 
 	if !meta.IsValid() {
-		return fmt.Errorf("invalid metadata")
+		return types.Transaction{}, fmt.Errorf("invalid metadata")
 	}
 
-	note, err := json.Marshal(meta)
+	data, err := meta.JSON()
 	if err != nil {
-		return fmt.Errorf("unable to convert metadata to JSON: %s", err)
+		return types.Transaction{}, err
 	}
 
-	txParams, err := a.algodClient.SuggestedParams().Do(ctx)
+	note, err := encodeNote(data, cfg)
 	if err != nil {
-		return fmt.Errorf("error getting suggested tx params: %s", err)
+		return types.Transaction{}, err
 	}
 
-	_, asset, err := a.indexerClient.LookupAssetByID(assetID).Do(ctx)
+	return a.buildConfigTxn(ctx, cfg, sender, assetID, note)
+}
+
+// buildConfigTxn builds the unsigned acfg transaction of the given asset carrying
+// note, preserving the asset's current manager, reserve, freeze and clawback.
+func (a *ARC69) buildConfigTxn(ctx context.Context, cfg *options, sender string, assetID uint64, note []byte) (types.Transaction, error) {
+	if cfg.algodClient == nil || cfg.indexerClient == nil {
+		return types.Transaction{}, ErrMissingClient
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("unable to fetch asset: %s", err)
+		return types.Transaction{}, fmt.Errorf("unable to fetch asset: %s", err)
+	}
+
+	if asset.Params.Manager == "" {
+		return types.Transaction{}, ErrAssetImmutable
+	}
+
+	var txParams types.SuggestedParams
+	err = observe(ctx, cfg, "algod.SuggestedParams", func(ctx context.Context) (err error) {
+		txParams, err = cfg.algodClient.SuggestedParams().Do(ctx)
+		return err
+	})
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("error getting suggested tx params: %s", err)
+	}
+
+	if cfg.validityWindow > 0 {
+		if cfg.validityWindow > maxValidityWindow {
+			return types.Transaction{}, fmt.Errorf("validity window of %d rounds exceeds the maximum of %d", cfg.validityWindow, maxValidityWindow)
+		}
+		txParams.LastRoundValid = txParams.FirstRoundValid + types.Round(cfg.validityWindow)
+	}
+
+	// Keep the current roles unless overridden, only allowing cleared roles when
+	// explicitly requested.
+	strict := true
+	roles := []struct {
+		name     string
+		current  string
+		override *string
+	}{
+		{"manager", asset.Params.Manager, cfg.manager},
+		{"reserve", asset.Params.Reserve, cfg.reserve},
+		{"freeze", asset.Params.Freeze, cfg.freeze},
+		{"clawback", asset.Params.Clawback, cfg.clawback},
+	}
+	addrs := make([]string, len(roles))
+	for i, role := range roles {
+		addrs[i] = role.current
+		if role.override == nil {
+			continue
+		}
+		addrs[i] = *role.override
+		if addrs[i] == "" {
+			strict = false
+		} else if _, err := types.DecodeAddress(addrs[i]); err != nil {
+			return types.Transaction{}, fmt.Errorf("invalid %s address: %s", role.name, err)
+		}
 	}
 
 	// Create asset config transaction to update ARC69 metadata
-	txn, err := future.MakeAssetConfigTxn(account.Address.String(), note, txParams, assetID, asset.Params.Manager, asset.Params.Reserve, asset.Params.Freeze, asset.Params.Clawback, true)
+	txn, err := future.MakeAssetConfigTxn(sender, note, txParams, assetID, addrs[0], addrs[1], addrs[2], addrs[3], strict)
+	if err != nil {
+		return types.Transaction{}, fmt.Errorf("error creating asset config transaction: %s", err)
+	}
+
+	if cfg.rekeyTo != "" {
+		txn.RekeyTo, err = types.DecodeAddress(cfg.rekeyTo)
+		if err != nil {
+			return types.Transaction{}, fmt.Errorf("invalid rekey-to address: %s", err)
+		}
+	}
+
+	if err := setFee(&txn, txParams); err != nil {
+		return types.Transaction{}, fmt.Errorf("error computing transaction fee: %s", err)
+	}
+
+	return txn, nil
+}
+
+// IsMutable reports whether the metadata of an asset can still be updated, which
+// is the case as long as its manager address has not been cleared.
+func (a *ARC69) IsMutable(ctx context.Context, assetID uint64, opts ...Option) (bool, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return false, ErrMissingClient
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch asset: %s", err)
+	}
+
+	return asset.Params.Manager != "", nil
+}
+
+// Helper function that sets the fee of txn from the suggested params, which is
+// its estimated size times the fee per byte floored at the network's minimum fee.
+// Unlike the fee set by the SDK, it accounts for changes made after building.
+func setFee(txn *types.Transaction, params types.SuggestedParams) error {
+	if params.FlatFee {
+		txn.Fee = params.Fee
+		return nil
+	}
+
+	size, err := transaction.EstimateSize(*txn)
+	if err != nil {
+		return err
+	}
+
+	minFee := params.MinFee
+	if minFee == 0 {
+		minFee = future.MinTxnFee
+	}
+
+	fee := size * uint64(params.Fee)
+	if fee < minFee {
+		fee = minFee
+	}
+	txn.Fee = types.MicroAlgos(fee)
+	return nil
+}
+
+// Update attempts to update the given ARC69 metadata for the given asset and
+// returns any errors.
+func (a *ARC69) Update(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata, opts ...Option) error {
+	_, err := a.UpdateDetailed(ctx, account, assetID, meta, opts...)
+	return err
+}
+
+// UpdateDetailed updates the metadata of an asset like Update and returns the
+// pending transaction information of the acfg transaction once it is confirmed.
+// When the confirmation is only found on the indexer, the returned information
+// holds just the confirmed round.
+func (a *ARC69) UpdateDetailed(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata, opts ...Option) (*models.PendingTransactionInfoResponse, error) {
+	cfg := a.config(opts)
+	txn, err := a.buildUpdate(ctx, cfg, account.Address.String(), assetID, meta)
 	if err != nil {
-		return fmt.Errorf("error creating asset config transaction: %s", err)
+		return nil, err
 	}
 
 	// Sign transaction
 	txID, signedTxn, err := crypto.SignTransaction(account.PrivateKey, txn)
 	if err != nil {
-		return fmt.Errorf("failed to sign transaction: %s", err)
+		return nil, fmt.Errorf("failed to sign transaction: %s", err)
+	}
+
+	info, err := a.submit(ctx, cfg, txID, signedTxn)
+	if err != nil {
+		return nil, err
+	}
+
+	a.InvalidateCache(assetID)
+	return info, nil
+}
+
+// UpdateIfUnchanged updates the metadata of an asset like Update, unless an acfg
+// transaction of the asset was confirmed after sinceRound, in which case
+// ErrConflict is returned and nothing is submitted. sinceRound is usually the
+// Round of the MetadataVersion the new metadata was derived from. The check is
+// made against the indexer right before submitting, so an update confirmed in
+// between is not detected.
+func (a *ARC69) UpdateIfUnchanged(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata, sinceRound uint64, opts ...Option) error {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return ErrMissingClient
+	}
+
+	var resp models.TransactionsResponse
+	err := observe(ctx, cfg, "indexer.LookupAssetTransactions", func(ctx context.Context) (err error) {
+		resp, err = cfg.indexerClient.LookupAssetTransactions(assetID).TxType("acfg").MinRound(sinceRound + 1).Limit(1).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to check for newer updates: %s", err)
+	}
+	if len(resp.Transactions) > 0 {
+		return ErrConflict
+	}
+
+	return a.Update(ctx, account, assetID, meta, opts...)
+}
+
+// UpdateWithSigner is like Update for senders whose keys are held outside of the
+// library, such as custodial services or hardware wallets. The unsigned update
+// transaction is passed to sign, which returns the signed transaction bytes that
+// are then submitted. The ID of the confirmed transaction is returned.
+func (a *ARC69) UpdateWithSigner(ctx context.Context, sender string, assetID uint64, meta *Metadata, sign func(txn types.Transaction) ([]byte, error), opts ...Option) (string, error) {
+	cfg := a.config(opts)
+	txn, err := a.buildUpdate(ctx, cfg, sender, assetID, meta)
+	if err != nil {
+		return "", err
+	}
+
+	signedTxn, err := sign(txn)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign transaction: %s", err)
+	}
+
+	txID := crypto.TransactionIDString(txn)
+	if _, err := a.submit(ctx, cfg, txID, signedTxn); err != nil {
+		return "", err
 	}
 
+	a.InvalidateCache(assetID)
+	return txID, nil
+}
+
+// submit sends the signed transaction bytes to the network, waits for the
+// transaction with the given ID to be confirmed and returns its pending
+// transaction information.
+func (a *ARC69) submit(ctx context.Context, cfg *options, txID string, signedTxn []byte) (*models.PendingTransactionInfoResponse, error) {
 	// Submit the transaction
-	_, err = a.algodClient.SendRawTransaction(signedTxn).Do(context.Background())
+	err := observe(ctx, cfg, "algod.SendRawTransaction", func(ctx context.Context) error {
+		_, err := cfg.algodClient.SendRawTransaction(signedTxn).Do(ctx)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send transaction: %s", err)
+		return nil, fmt.Errorf("failed to send transaction: %s", err)
 	}
 
 	// Wait for confirmation
-	if err := waitForConfirmation(txID, a.algodClient, 4); err != nil {
-		return fmt.Errorf("error waiting for confirmation on txID: %s", txID)
+	info, err := waitForConfirmation(ctx, cfg, txID, 4)
+	if err != nil {
+		return nil, fmt.Errorf("error waiting for confirmation on txID %s: %s", txID, err)
+	}
+
+	return info, nil
+}
+
+// Equal reports whether m and other hold the same metadata. Numbers held in
+// properties are compared by value, whether they are json.Number, as in fetched
+// metadata, or Go numbers.
+func (m *Metadata) Equal(other *Metadata) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+	return reflect.DeepEqual(m.withNormalizedNumbers(), other.withNormalizedNumbers())
+}
+
+// EqualIgnoring reports whether m and other hold the same metadata once the given
+// paths are left out of the comparison. A path is either the JSON name of a
+// top-level field, such as "description" or "attributes", or "properties"
+// followed by the "." delimited path of a property, such as "properties.p1.p2",
+// which ignores that property and everything nested under it. Paths that match
+// nothing are ignored. Nil and empty maps or slices, such as the properties left
+// empty once their only key is ignored, compare equal.
+func (m *Metadata) EqualIgnoring(other *Metadata, ignorePaths []string) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+
+	a, b := m.clone(), other.clone()
+	for _, path := range ignorePaths {
+		a.ignore(path)
+		b.ignore(path)
+	}
+	a.dropEmpty()
+	b.dropEmpty()
+	return a.Equal(b)
+}
+
+// Helper function that replaces the empty properties, attributes and nested
+// property maps and slices of m by nil.
+func (m *Metadata) dropEmpty() {
+	props, _ := dropEmptyValue(m.Properties).(map[string]interface{})
+	m.Properties = props
+	if len(m.Attributes) == 0 {
+		m.Attributes = nil
+	}
+}
+
+// Helper function that returns v with every empty map or slice replaced by nil.
+// v must not be shared since its maps and slices are modified in place.
+func dropEmptyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for k, val := range v {
+			v[k] = dropEmptyValue(val)
+		}
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		for i, val := range v {
+			v[i] = dropEmptyValue(val)
+		}
 	}
+	return v
+}
+
+// Helper function that clears the field or deletes the property at path.
+func (m *Metadata) ignore(path string) {
+	switch path {
+	case "standard":
+		m.Standard = ""
+	case "description":
+		m.Description = ""
+	case "external_url":
+		m.ExternalURL = ""
+	case "media_url":
+		m.MediaURL = ""
+	case "properties":
+		m.Properties = nil
+		m.arrayProperties = false
+	case "mime_type":
+		m.MimeType = ""
+	case "attributes":
+		m.Attributes = nil
+	}
+
+	if !strings.HasPrefix(path, "properties.") {
+		return
+	}
+	keys := strings.Split(strings.TrimPrefix(path, "properties."), ".")
+	props := m.Properties
+	for _, key := range keys[:len(keys)-1] {
+		nested, ok := props[key].(map[string]interface{})
+		if !ok {
+			return
+		}
+		props = nested
+	}
+	delete(props, keys[len(keys)-1])
+}
+
+// JSON returns the compact JSON encoding of m, with its fields in the order used
+// by the ARC69 spec and the standard written in lowercase. This is the form
+// stored in notes by Update.
+func (m *Metadata) JSON() ([]byte, error) {
+	data, err := json.Marshal(m.canonical())
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert metadata to JSON: %s", err)
+	}
+	return data, nil
+}
+
+// JSONIndent returns the JSON encoding of m like JSON, indented for display.
+func (m *Metadata) JSONIndent() ([]byte, error) {
+	data, err := json.MarshalIndent(m.canonical(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert metadata to JSON: %s", err)
+	}
+	return data, nil
+}
+
+// canonical returns m with an ARC69 standard spelled in lowercase, copying m only
+// if it has to be changed.
+func (m *Metadata) canonical() *Metadata {
+	if m.Standard == "arc69" || !m.IsValid() {
+		return m
+	}
+	c := *m
+	c.Standard = "arc69"
+	return &c
+}
+
+// RoundTripsCleanly reports whether m is unchanged by encoding it with JSON and
+// parsing the result back, as happens when it is stored in a note and fetched.
+// Property values that only survive as a different type (ex. a struct decoding
+// as a map or a time.Time decoding as a string) and strings that are not valid
+// UTF-8 are reported as discrepancies in the returned error. Numbers are compared
+// by value since parsed metadata holds them as json.Number.
+func (m *Metadata) RoundTripsCleanly() (bool, error) {
+	data, err := m.JSON()
+	if err != nil {
+		return false, err
+	}
+	parsed, err := parseNote(data, &options{})
+	if err != nil {
+		return false, err
+	}
+
+	want := m.canonical()
+	var discrepancies []string
+	fields := []struct {
+		name      string
+		want, got string
+	}{
+		{"standard", want.Standard, parsed.Standard},
+		{"description", want.Description, parsed.Description},
+		{"external_url", want.ExternalURL, parsed.ExternalURL},
+		{"media_url", want.MediaURL, parsed.MediaURL},
+		{"mime_type", want.MimeType, parsed.MimeType},
+	}
+	for _, field := range fields {
+		if field.want != field.got {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s: %q decodes as %q", field.name, field.want, field.got))
+		}
+	}
+
+	if want.Properties == nil {
+		if parsed.Properties != nil {
+			discrepancies = append(discrepancies, "properties: null decodes as an object")
+		}
+	} else {
+		discrepancies = append(discrepancies, roundTripDiscrepancies("properties", reflect.ValueOf(want.Properties), parsed.Properties)...)
+	}
+
+	if len(want.Attributes) != len(parsed.Attributes) {
+		discrepancies = append(discrepancies, fmt.Sprintf("attributes: %d attributes decode as %d", len(want.Attributes), len(parsed.Attributes)))
+	} else {
+		for i, attr := range want.Attributes {
+			if !sameAttribute(attr, parsed.Attributes[i]) {
+				discrepancies = append(discrepancies, fmt.Sprintf("attributes[%d]: %+v decodes as %+v", i, attr, parsed.Attributes[i]))
+			}
+		}
+	}
+
+	if len(discrepancies) > 0 {
+		return false, fmt.Errorf("metadata does not round-trip: %s", strings.Join(discrepancies, "; "))
+	}
+	return true, nil
+}
+
+// Helper function that compares a property value with the value parsed from its
+// JSON encoding and describes every difference found under path.
+func roundTripDiscrepancies(path string, want reflect.Value, got interface{}) []string {
+	for want.Kind() == reflect.Interface && !want.IsNil() {
+		want = want.Elem()
+	}
+	mismatch := func() []string {
+		if !want.IsValid() || want.Kind() == reflect.Interface {
+			return []string{fmt.Sprintf("%s: null decodes as %T", path, got)}
+		}
+		return []string{fmt.Sprintf("%s: %s value decodes as %T", path, want.Type(), got)}
+	}
+
+	switch got := got.(type) {
+	case nil:
+		if want.IsValid() && want.Kind() != reflect.Interface {
+			return mismatch()
+		}
+	case json.Number:
+		if want.Type() == reflect.TypeOf(json.Number("")) {
+			if want.String() != got.String() {
+				return []string{fmt.Sprintf("%s: %s decodes as %s", path, want.String(), got)}
+			}
+			return nil
+		}
+		switch want.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return nil
+		}
+		return mismatch()
+	case string:
+		if !want.IsValid() || want.Type() != reflect.TypeOf("") {
+			return mismatch()
+		}
+		if want.String() != got {
+			return []string{fmt.Sprintf("%s: %q decodes as %q", path, want.String(), got)}
+		}
+	case bool:
+		if !want.IsValid() || want.Type() != reflect.TypeOf(true) {
+			return mismatch()
+		}
+	case map[string]interface{}:
+		if !want.IsValid() || want.Kind() != reflect.Map || want.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		keys := want.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
 
+		var discrepancies []string
+		for _, k := range keys {
+			discrepancies = append(discrepancies, roundTripDiscrepancies(path+"."+k.String(), want.MapIndex(k), got[k.String()])...)
+		}
+		return discrepancies
+	case []interface{}:
+		if !want.IsValid() || (want.Kind() != reflect.Slice && want.Kind() != reflect.Array) {
+			return mismatch()
+		}
+
+		var discrepancies []string
+		for i := 0; i < want.Len() && i < len(got); i++ {
+			discrepancies = append(discrepancies, roundTripDiscrepancies(fmt.Sprintf("%s[%d]", path, i), want.Index(i), got[i])...)
+		}
+		return discrepancies
+	}
 	return nil
 }
 
-// IsValid checks that the metadata is valid.
+// ToMap returns m as a generic map keyed by the ARC69 field names (ex. "media_url"),
+// as it would be decoded from its JSON encoding, for use with templating engines
+// and JSON tooling.
+func (m *Metadata) ToMap() (map[string]interface{}, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert metadata to JSON: %s", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("unable to convert metadata to map: %s", err)
+	}
+
+	return out, nil
+}
+
+// SizeBreakdown returns the number of bytes each top-level field (ex. "properties")
+// takes in the JSON encoding of m, counting its key, colon and value, which helps
+// find what to trim when the metadata does not fit in a note. The full encoding is
+// the sum of the fields plus the enclosing braces and the commas separating them.
+// A nil map is returned if m cannot be encoded.
+func (m *Metadata) SizeBreakdown() map[string]int {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+
+	sizes := make(map[string]int, len(fields))
+	for key, value := range fields {
+		quoted, _ := json.Marshal(key)
+		sizes[key] = len(quoted) + 1 + len(value)
+	}
+
+	return sizes
+}
+
+// clone returns a deep copy of m.
+func (m *Metadata) clone() *Metadata {
+	c := *m
+	if m.Properties != nil {
+		c.Properties = cloneValue(m.Properties).(map[string]interface{})
+	}
+	if m.Attributes != nil {
+		c.Attributes = append([]Attribute(nil), m.Attributes...)
+	}
+	return &c
+}
+
+// Helper function to deep copy the maps and slices of a property value.
+func cloneValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			c[k] = cloneValue(val)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, val := range v {
+			c[i] = cloneValue(val)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// withNormalizedNumbers returns a copy of m whose property and attribute numbers
+// are replaced by their canonical json.Number, as returned by normalizeNumbers.
+func (m *Metadata) withNormalizedNumbers() *Metadata {
+	c := *m
+	if m.Properties != nil {
+		c.Properties = normalizeNumbers(m.Properties).(map[string]interface{})
+	}
+	if m.Attributes != nil {
+		c.Attributes = make([]Attribute, len(m.Attributes))
+		for i, attr := range m.Attributes {
+			attr.Value = normalizeNumbers(attr.Value)
+			c.Attributes[i] = attr
+		}
+	}
+	return &c
+}
+
+// Helper function that returns a copy of a property value where every number,
+// held as a json.Number or any Go integer or float, is replaced by a json.Number
+// in canonical form, so that equal numbers compare equal whatever their type or
+// formatting (ex. 3, 3.0 and json.Number("3e0")).
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		c := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			c[k] = normalizeNumbers(val)
+		}
+		return c
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		c := make([]interface{}, len(v))
+		for i, val := range v {
+			c[i] = normalizeNumbers(val)
+		}
+		return c
+	case json.Number:
+		s := v.String()
+		if !strings.ContainsAny(s, ".eE") {
+			if i, ok := new(big.Int).SetString(s, 10); ok {
+				return json.Number(i.String())
+			}
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return v
+		}
+		return canonicalFloat(f)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return canonicalFloat(rv.Float())
+	}
+	return v
+}
+
+// Helper function that returns the canonical json.Number of f: integral values
+// are written out in full and others in the shortest form that parses back to f.
+func canonicalFloat(f float64) json.Number {
+	if !math.IsInf(f, 0) && f == math.Trunc(f) {
+		i, _ := new(big.Float).SetFloat64(f).Int(nil)
+		return json.Number(i.String())
+	}
+	return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// IsValid checks that the metadata is valid. The standard is matched
+// case-insensitively, so "ARC69" and "Arc69" are accepted.
 func (m *Metadata) IsValid() bool {
-	return m.Standard == "arc69"
+	return strings.EqualFold(m.Standard, "arc69")
 }
 
 // Property searches through the m.Properties for the requested property path.
 // Path should a be "." delimited path to a property (ex. "p1.p2.p3.p4"). If the
 // property is found we return the value as an interface, otherwise an error is returned.
+// Numbers in fetched or parsed metadata are returned as json.Number; PropertyInt
+// and PropertyFloat convert them.
 func (m *Metadata) Property(path string) (interface{}, error) {
 	if path == "" {
 		return nil, fmt.Errorf("no path provided")
 	}
+	if m.Properties == nil {
+		return nil, fmt.Errorf("unable to get property %s: no properties", path)
+	}
 	val, err := walkProperties(reflect.ValueOf(m.Properties), strings.Split(path, "."), []string{})
 	if err != nil {
 		return nil, fmt.Errorf("unable to get property %s: %s", path, err)
@@ -154,6 +1083,261 @@ func (m *Metadata) Property(path string) (interface{}, error) {
 	return val, nil
 }
 
+// PropertyInt returns the property at the given path as an int64. The property
+// must be an integer, held as a json.Number or a Go integer or float, and must
+// fit in an int64 without losing precision.
+func (m *Metadata) PropertyInt(path string) (int64, error) {
+	val, err := m.Property(path)
+	if err != nil {
+		return 0, err
+	}
+
+	switch v := val.(type) {
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return 0, fmt.Errorf("property %s is not an integer: %s", path, v)
+		}
+		return i, nil
+	case float64:
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return 0, fmt.Errorf("property %s is not an integer: %v", path, v)
+		}
+		return int64(v), nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, fmt.Errorf("property %s overflows int64: %d", path, rv.Uint())
+		}
+		return int64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("property %s is not a number: %v", path, val)
+}
+
+// PropertyFloat returns the property at the given path as a float64. The property
+// must be a number, held as a json.Number or a Go integer or float.
+func (m *Metadata) PropertyFloat(path string) (float64, error) {
+	val, err := m.Property(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if v, ok := val.(json.Number); ok {
+		f, err := v.Float64()
+		if err != nil {
+			return 0, fmt.Errorf("property %s is not a number: %s", path, v)
+		}
+		return f, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), nil
+	}
+	return 0, fmt.Errorf("property %s is not a number: %v", path, val)
+}
+
+// PropertyFold is like Property except that each segment of the path matches keys
+// case-insensitively, so "Background" finds a "background" key. A key matching the
+// segment exactly takes precedence; otherwise an error is returned if several keys
+// differ from it only by case, since the match would be ambiguous.
+func (m *Metadata) PropertyFold(path string) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no path provided")
+	}
+	if m.Properties == nil {
+		return nil, fmt.Errorf("unable to get property %s: no properties", path)
+	}
+
+	keys := strings.Split(path, ".")
+	var val interface{} = m.Properties
+	for i, key := range keys {
+		seen := strings.Join(keys[:i], ".")
+		props, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unable to get property %s: property %s is not a map", path, seen)
+		}
+
+		if v, ok := props[key]; ok {
+			val = v
+			continue
+		}
+
+		var matches []string
+		for k := range props {
+			if strings.EqualFold(k, key) {
+				matches = append(matches, k)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return nil, fmt.Errorf("unable to get property %s: property %s is not valid", path, strings.Join(keys[:i+1], "."))
+		case 1:
+			val = props[matches[0]]
+		default:
+			sort.Strings(matches)
+			return nil, fmt.Errorf("unable to get property %s: %s is ambiguous between keys %q", path, strings.Join(keys[:i+1], "."), matches)
+		}
+	}
+
+	return val, nil
+}
+
+// SetProperty sets the property at the given "." delimited path of m.Properties to
+// value, creating m.Properties and any missing intermediate maps. An error is
+// returned if an intermediate segment of the path holds a value that is not a map.
+func (m *Metadata) SetProperty(path string, value interface{}) error {
+	if path == "" {
+		return fmt.Errorf("no path provided")
+	}
+	if m.Properties == nil {
+		m.Properties = make(map[string]interface{})
+	}
+
+	keys := strings.Split(path, ".")
+	props := m.Properties
+	for i, key := range keys[:len(keys)-1] {
+		next, ok := props[key]
+		if !ok {
+			child := make(map[string]interface{})
+			props[key] = child
+			props = child
+			continue
+		}
+
+		child, ok := next.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unable to set property %s: property %s is not a map", path, strings.Join(keys[:i+1], "."))
+		}
+		props = child
+	}
+	props[keys[len(keys)-1]] = value
+
+	return nil
+}
+
+// DeleteProperty removes the property at the given "." delimited path from
+// m.Properties. An error is returned if the property does not exist or if an
+// intermediate segment of the path is not a map. When prune is true, maps left
+// empty by the deletion are removed as well.
+func (m *Metadata) DeleteProperty(path string, prune bool) error {
+	if path == "" {
+		return fmt.Errorf("no path provided")
+	}
+	if err := deleteProperty(m.Properties, strings.Split(path, "."), []string{}, prune); err != nil {
+		return fmt.Errorf("unable to delete property %s: %s", path, err)
+	}
+
+	return nil
+}
+
+// Helper function to delete a property from the metadata properties map.
+func deleteProperty(props map[string]interface{}, keys []string, seenKeys []string, prune bool) error {
+	seenKeys = append(seenKeys, keys[0])
+	val, ok := props[keys[0]]
+	if !ok {
+		return fmt.Errorf("property %s is not valid", strings.Join(seenKeys, "."))
+	}
+
+	if len(keys) == 1 {
+		delete(props, keys[0])
+		return nil
+	}
+
+	nested, ok := val.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("property %s is not a map", strings.Join(seenKeys, "."))
+	}
+
+	if err := deleteProperty(nested, keys[1:], seenKeys, prune); err != nil {
+		return err
+	}
+
+	if prune && len(nested) == 0 {
+		delete(props, keys[0])
+	}
+	return nil
+}
+
+// PropertyPaths returns the sorted "." delimited paths to every leaf value in
+// m.Properties, in the form accepted by Property. Nested maps are walked
+// recursively, while any other value, including a slice, is treated as a leaf.
+// Empty nested maps contribute no paths.
+func (m *Metadata) PropertyPaths() []string {
+	var paths []string
+	visitProperties(m.Properties, "", func(path string, v interface{}) {
+		if _, ok := v.(map[string]interface{}); !ok {
+			paths = append(paths, path)
+		}
+	})
+	sort.Strings(paths)
+	return paths
+}
+
+// PropertyTypes returns the type of every leaf value in m.Properties keyed by its
+// path as returned by PropertyPaths. Types are named as in JSON: "string",
+// "number", "bool", "object", "array" and "null". Values with no JSON type, such
+// as channels or functions, are reported as "unknown". Empty nested maps are
+// reported as "object" leaves.
+func (m *Metadata) PropertyTypes() map[string]string {
+	kinds := make(map[string]string)
+	visitProperties(m.Properties, "", func(path string, v interface{}) {
+		kinds[path] = propertyType(v)
+	})
+	return kinds
+}
+
+// Helper function that returns the JSON type name of a property value.
+func propertyType(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	if _, ok := v.(json.Number); ok {
+		return "number"
+	}
+
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "unknown"
+	}
+}
+
+// Helper function that calls fn with the path and value of every leaf of a
+// properties map. Nested maps are walked recursively and only passed to fn when
+// they are empty.
+func visitProperties(props map[string]interface{}, prefix string, fn func(path string, v interface{})) {
+	for k, v := range props {
+		path := prefix + k
+		if nested, ok := v.(map[string]interface{}); ok && len(nested) > 0 {
+			visitProperties(nested, path+".", fn)
+			continue
+		}
+		fn(path, v)
+	}
+}
+
 // Helper function to travers through the metadata properties map.
 func walkProperties(v reflect.Value, keys []string, seenKeys []string) (interface{}, error) {
 	if !v.IsValid() {
@@ -175,38 +1359,69 @@ func walkProperties(v reflect.Value, keys []string, seenKeys []string) (interfac
 	return walkProperties(v.MapIndex(reflect.ValueOf(keys[0])), keys[1:], append(seenKeys, keys[0]))
 }
 
-// Utility function that waits for a given txId to be confirmed by the network
-func waitForConfirmation(txID string, client *algod.Client, timeout uint64) error {
-	pt := new(models.PendingTransactionInfoResponse)
-	if client == nil || txID == "" || timeout < 0 {
-		return fmt.Errorf("Bad arguments for waitForConfirmation")
-
+// Utility function that waits for a given txId to be confirmed by the network.
+// If algod does not report the confirmation within timeout rounds, for instance
+// because the node restarted and lost its pending pool, the indexer is consulted
+// before giving up. Each call is made through observe, so it honors ctx, the
+// default timeout, the rate limit and Close, and is reported to the Observer.
+func waitForConfirmation(ctx context.Context, cfg *options, txID string, timeout uint64) (*models.PendingTransactionInfoResponse, error) {
+	client := cfg.algodClient
+	if client == nil || txID == "" {
+		return nil, fmt.Errorf("Bad arguments for waitForConfirmation")
 	}
 
-	status, err := client.Status().Do(context.Background())
+	var status models.NodeStatus
+	err := observe(ctx, cfg, "algod.Status", func(ctx context.Context) (err error) {
+		status, err = client.Status().Do(ctx)
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("error getting algod status: %s", err)
+		return nil, fmt.Errorf("error getting algod status: %s", err)
 	}
 	startRound := status.LastRound + 1
 	currentRound := startRound
 
 	for currentRound < (startRound + timeout) {
-
-		*pt, _, err = client.PendingTransactionInformation(txID).Do(context.Background())
+		var pt models.PendingTransactionInfoResponse
+		err := observe(ctx, cfg, "algod.PendingTransactionInformation", func(ctx context.Context) (err error) {
+			pt, _, err = client.PendingTransactionInformation(txID).Do(ctx)
+			return err
+		})
 		if err != nil {
-			return fmt.Errorf("error getting pending transaction: %s", err)
+			return nil, fmt.Errorf("error getting pending transaction: %s", err)
 		}
 		if pt.ConfirmedRound > 0 {
 			log.Printf("Transaction %s confirmed in round %d\n", txID, pt.ConfirmedRound)
-			return nil
+			return &pt, nil
 		}
 		if pt.PoolError != "" {
-			return fmt.Errorf("There was a pool error, then the transaction has been rejected")
+			return nil, fmt.Errorf("There was a pool error, then the transaction has been rejected")
 		}
 		log.Printf("Waiting for confirmation...\n")
-		status, err = client.StatusAfterBlock(currentRound).Do(context.Background())
+		err = observe(ctx, cfg, "algod.StatusAfterBlock", func(ctx context.Context) (err error) {
+			_, err = client.StatusAfterBlock(currentRound).Do(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error waiting for round %d: %s", currentRound, err)
+		}
 		currentRound++
 	}
 
-	return fmt.Errorf("Tx not found in round range")
+	if cfg.indexerClient != nil {
+		var resp models.TransactionResponse
+		err := observe(ctx, cfg, "indexer.LookupTransaction", func(ctx context.Context) (err error) {
+			resp, err = cfg.indexerClient.LookupTransaction(txID).Do(ctx)
+			return err
+		})
+		if err == nil && resp.Transaction.ConfirmedRound > 0 {
+			log.Printf("Transaction %s confirmed in round %d\n", txID, resp.Transaction.ConfirmedRound)
+			return &models.PendingTransactionInfoResponse{ConfirmedRound: resp.Transaction.ConfirmedRound}, nil
+		}
+		if err == ErrClosed || ctx.Err() != nil {
+			return nil, fmt.Errorf("error looking up transaction: %s", err)
+		}
+	}
+
+	return nil, fmt.Errorf("Tx not found in round range")
 }
@@ -1,8 +1,16 @@
 package arc69
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
 )
 
 func checkProperty(name, want string, meta *Metadata, t *testing.T) {
@@ -58,6 +66,176 @@ func TestMetadataPropertyNotFound(t *testing.T) {
 	}
 }
 
+func TestMetadataPropertyNoProperties(t *testing.T) {
+	meta := &Metadata{Standard: "arc69"}
+
+	for _, path := range []string{"a", "a.b"} {
+		_, got := meta.Property(path)
+		want := fmt.Errorf("unable to get property %s: no properties", path)
+		if got == nil || got.Error() != want.Error() {
+			t.Errorf("Property(%q) got error: %v, want error: %s", path, got, want)
+		}
+	}
+}
+
+func TestMetadataPropertyPaths(t *testing.T) {
+	meta := &Metadata{
+		Properties: map[string]interface{}{
+			"a": "aa",
+			"b": map[string]interface{}{"bb": "bbb"},
+			"c": map[string]interface{}{"cc": map[string]interface{}{"ccc": "cccc"}},
+			"d": []interface{}{"d1", "d2"},
+		},
+	}
+
+	got := meta.PropertyPaths()
+	want := []string{"a", "b.bb", "c.cc.ccc", "d"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PropertyPaths() = %q, want %q", got, want)
+	}
+}
+
+func TestMetadataEqualNumbers(t *testing.T) {
+	fetched := &Metadata{Standard: "arc69", Properties: map[string]interface{}{
+		"level": json.Number("3"),
+		"stats": map[string]interface{}{"ratio": json.Number("0.50"), "list": []interface{}{json.Number("1e2")}},
+	}}
+	local := &Metadata{Standard: "arc69", Properties: map[string]interface{}{
+		"level": float64(3),
+		"stats": map[string]interface{}{"ratio": 0.5, "list": []interface{}{100}},
+	}}
+	if !fetched.Equal(local) {
+		t.Errorf("Equal() of the same numbers held as json.Number and Go numbers = false, want true")
+	}
+	if !fetched.EqualIgnoring(local, nil) {
+		t.Errorf("EqualIgnoring() of the same numbers held as json.Number and Go numbers = false, want true")
+	}
+
+	local.Properties["level"] = 3.5
+	if fetched.Equal(local) {
+		t.Errorf("Equal() of different numbers = true, want false")
+	}
+}
+
+func TestMetadataEqualIgnoring(t *testing.T) {
+	a := &Metadata{
+		Standard:    "arc69",
+		Description: "first",
+		Properties:  map[string]interface{}{"name": "hero", "sync": map[string]interface{}{"at": "2022-01-01", "by": "bot"}},
+	}
+	b := &Metadata{
+		Standard:    "arc69",
+		Description: "second",
+		Properties:  map[string]interface{}{"name": "hero", "sync": map[string]interface{}{"at": "2022-02-01", "by": "bot"}},
+	}
+
+	tests := []struct {
+		ignore []string
+		want   bool
+	}{
+		{nil, false},
+		{[]string{"properties.sync.at"}, false},
+		{[]string{"properties.sync.at", "description"}, true},
+		{[]string{"properties.sync", "description"}, true},
+		{[]string{"properties", "description"}, true},
+		{[]string{"properties.name", "description"}, false},
+		{[]string{"properties.sync.at.deeper", "description"}, false},
+	}
+	for _, test := range tests {
+		if got := a.EqualIgnoring(b, test.ignore); got != test.want {
+			t.Errorf("EqualIgnoring(%q) = %t, want %t", test.ignore, got, test.want)
+		}
+	}
+
+	synced := &Metadata{Standard: "arc69", Properties: map[string]interface{}{"sync": "2022-01-01"}, Attributes: []Attribute{}}
+	plain := &Metadata{Standard: "arc69"}
+	if !synced.EqualIgnoring(plain, []string{"properties.sync"}) || !plain.EqualIgnoring(synced, []string{"properties.sync"}) {
+		t.Errorf("EqualIgnoring() of metadata differing only in an ignored property and empty attributes = false, want true")
+	}
+	empty := &Metadata{Standard: "arc69", Properties: map[string]interface{}{"stats": map[string]interface{}{}, "tags": []interface{}{}}}
+	nilled := &Metadata{Standard: "arc69", Properties: map[string]interface{}{"stats": map[string]interface{}(nil), "tags": []interface{}(nil)}}
+	if !empty.EqualIgnoring(nilled, nil) {
+		t.Errorf("EqualIgnoring() of empty and nil nested properties = false, want true")
+	}
+	if _, ok := synced.Properties["sync"]; !ok {
+		t.Errorf("EqualIgnoring() modified its receiver")
+	}
+
+	if a.Description != "first" || a.Properties["sync"].(map[string]interface{})["at"] != "2022-01-01" {
+		t.Errorf("EqualIgnoring() modified its receiver to %+v", a)
+	}
+}
+
+func TestMetadataPropertyTypes(t *testing.T) {
+	meta := &Metadata{
+		Properties: map[string]interface{}{
+			"name":  "hero",
+			"level": json.Number("3"),
+			"speed": 1.5,
+			"shiny": true,
+			"tags":  []interface{}{"a", "b"},
+			"stats": map[string]interface{}{"hp": json.Number("10"), "extra": map[string]interface{}{}},
+			"none":  nil,
+			"count": uint8(7),
+			"ch":    make(chan int),
+		},
+	}
+
+	got := meta.PropertyTypes()
+	want := map[string]string{
+		"name":        "string",
+		"level":       "number",
+		"speed":       "number",
+		"shiny":       "bool",
+		"tags":        "array",
+		"stats.hp":    "number",
+		"stats.extra": "object",
+		"none":        "null",
+		"count":       "number",
+		"ch":          "unknown",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PropertyTypes() = %v, want %v", got, want)
+	}
+}
+
+func TestMetadataDeleteProperty(t *testing.T) {
+	meta := &Metadata{
+		Properties: map[string]interface{}{
+			"a": "aa",
+			"b": map[string]interface{}{"bb": "bbb", "bc": "bcc"},
+			"c": map[string]interface{}{"cc": map[string]interface{}{"ccc": "cccc"}},
+		},
+	}
+
+	if err := meta.DeleteProperty("a", false); err != nil {
+		t.Errorf("DeleteProperty(\"a\") failed with error: %s, want success", err)
+	}
+	if err := meta.DeleteProperty("b.bb", false); err != nil {
+		t.Errorf("DeleteProperty(\"b.bb\") failed with error: %s, want success", err)
+	}
+	if err := meta.DeleteProperty("c.cc.ccc", true); err != nil {
+		t.Errorf("DeleteProperty(\"c.cc.ccc\") failed with error: %s, want success", err)
+	}
+
+	want := map[string]interface{}{"b": map[string]interface{}{"bc": "bcc"}}
+	if !reflect.DeepEqual(meta.Properties, want) {
+		t.Errorf("Properties = %v, want %v", meta.Properties, want)
+	}
+
+	err := meta.DeleteProperty("b.missing", false)
+	wantErr := fmt.Errorf("unable to delete property b.missing: property b.missing is not valid")
+	if err == nil || err.Error() != wantErr.Error() {
+		t.Errorf("got error: %v, want error: %s", err, wantErr)
+	}
+
+	err = meta.DeleteProperty("b.bc.x", false)
+	wantErr = fmt.Errorf("unable to delete property b.bc.x: property b.bc is not a map")
+	if err == nil || err.Error() != wantErr.Error() {
+		t.Errorf("got error: %v, want error: %s", err, wantErr)
+	}
+}
+
 func TestMetadataIsValid(t *testing.T) {
 	validMeta := &Metadata{Standard: "arc69"}
 	invalidMeta := &Metadata{Standard: "arc68"}
@@ -69,4 +247,430 @@ func TestMetadataIsValid(t *testing.T) {
 	if invalidMeta.IsValid() != false {
 		t.Errorf("IsValid(%+v) = true, want false", *invalidMeta)
 	}
+
+	for _, standard := range []string{"ARC69", "Arc69"} {
+		meta := &Metadata{Standard: standard}
+		if !meta.IsValid() {
+			t.Errorf("IsValid(%+v) = false, want true", *meta)
+		}
+		if err := meta.Validate(); err != nil {
+			t.Errorf("Validate(%+v) failed with error: %s, want success", *meta, err)
+		}
+	}
+}
+
+func TestUpdateDetailed(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	info, err := a.UpdateDetailed(context.Background(), account, 1, &Metadata{Standard: "arc69"})
+	if err != nil {
+		t.Fatalf("UpdateDetailed() failed with error: %s, want success", err)
+	}
+	if info == nil || info.ConfirmedRound != 101 {
+		t.Errorf("UpdateDetailed() = %+v, want confirmed round 101", info)
+	}
+}
+
+func TestUpdateWritesLowercaseStandard(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	meta := &Metadata{Standard: "ARC69", Description: "shouty"}
+	if err := a.Update(context.Background(), account, 1, meta); err != nil {
+		t.Fatalf("Update() failed with error: %s, want success", err)
+	}
+	if note, want := algod.sentTxns()[0].Txn.Note, `{"standard":"arc69",`; !bytes.HasPrefix(note, []byte(want)) {
+		t.Errorf("Update() note = %s, want prefix %s", note, want)
+	}
+	if meta.Standard != "ARC69" {
+		t.Errorf("Update() changed the standard of its argument to %q, want it left as \"ARC69\"", meta.Standard)
+	}
+
+	idx.addNote(2, 10, []byte(`{"standard":"Arc69","description":"mixed"}`))
+	got, err := a.Fetch(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Fetch() of \"Arc69\" metadata failed with error: %s, want success", err)
+	}
+	if got.Description != "mixed" {
+		t.Errorf("Fetch() = %+v, want description \"mixed\"", got)
+	}
+}
+
+func TestFetchRequestCount(t *testing.T) {
+	idx := newFakeIndexer(t)
+	for round := uint64(1); round <= 50; round++ {
+		idx.addMetadata(t, 1, round, &Metadata{Standard: "arc69", Description: fmt.Sprintf("v%d", round)})
+	}
+
+	got, err := New(nil, idx.client(t)).Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if got.Description != "v50" {
+		t.Errorf("Fetch() = %q, want \"v50\"", got.Description)
+	}
+	if reqs := idx.requestsTo("/v2/assets/1/transactions"); len(reqs) != 1 {
+		t.Errorf("Fetch() of an asset with 50 acfg transactions made %d indexer requests, want 1", len(reqs))
+	}
+}
+
+func TestFetchWithClients(t *testing.T) {
+	mainnet := newFakeIndexer(t)
+	mainnet.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "mainnet"})
+	testnet := newFakeIndexer(t)
+	testnet.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "testnet"})
+	a := New(nil, mainnet.client(t))
+
+	got, err := a.Fetch(context.Background(), 1, WithClients(nil, testnet.client(t)))
+	if err != nil {
+		t.Fatalf("Fetch(WithClients()) failed with error: %s, want success", err)
+	}
+	if got.Description != "testnet" {
+		t.Errorf("Fetch(WithClients()) = %q, want metadata from the override clients", got.Description)
+	}
+
+	got, err = a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if got.Description != "mainnet" {
+		t.Errorf("Fetch() = %q, want metadata from the default clients", got.Description)
+	}
+}
+
+func TestFetchByTxID(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "by txid"})
+	idx.addNote(1, 11, []byte(`{"standard":"arc68"}`))
+	idx.addTxn(1, models.Transaction{Id: "pay-tx", Type: "pay", Note: []byte(`{"standard":"arc69"}`)})
+	a := New(nil, idx.client(t))
+
+	got, err := a.FetchByTxID(context.Background(), "tx-1-10")
+	if err != nil {
+		t.Fatalf("FetchByTxID() failed with error: %s, want success", err)
+	}
+	if got.Description != "by txid" {
+		t.Errorf("FetchByTxID() = %+v, want metadata from tx-1-10", got)
+	}
+
+	for _, txID := range []string{"tx-1-11", "pay-tx", "missing-tx"} {
+		if _, err := a.FetchByTxID(context.Background(), txID); err == nil {
+			t.Errorf("FetchByTxID(%q) succeeded, want error", txID)
+		}
+	}
+}
+
+func TestFetchSummary(t *testing.T) {
+	props := make(map[string]interface{})
+	for i := 0; i < 1000; i++ {
+		props[fmt.Sprintf("p%d", i)] = map[string]interface{}{"nested": i}
+	}
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{
+		Standard:    "arc69",
+		Description: "summary",
+		MediaURL:    "ipfs://media",
+		MimeType:    "image/png",
+		Properties:  props,
+		Attributes:  []Attribute{{TraitType: "Background", Value: "Blue"}},
+	})
+
+	got, err := New(nil, idx.client(t)).FetchSummary(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchSummary() failed with error: %s, want success", err)
+	}
+
+	want := &MetadataSummary{Standard: "arc69", Description: "summary", MediaURL: "ipfs://media", MimeType: "image/png"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchSummary() = %+v, want %+v", got, want)
+	}
+
+	idx.addNote(1, 20, []byte("not metadata"))
+	if got, err = New(nil, idx.client(t)).FetchSummary(context.Background(), 1); err != nil {
+		t.Fatalf("FetchSummary() of asset with a newer non-ARC69 note failed with error: %s, want success", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchSummary() of asset with a newer non-ARC69 note = %+v, want %+v", got, want)
+	}
+	if _, err := New(nil, idx.client(t)).FetchSummary(context.Background(), 1, WithLatestOnly()); err == nil {
+		t.Errorf("FetchSummary(WithLatestOnly()) of asset with a non-ARC69 latest note succeeded, want error")
+	}
+}
+
+func TestWaitForConfirmationIndexerBackstop(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69"})
+	algod := newFakeAlgod(t, nil)
+	algod.unconfirmed = true
+
+	info, err := waitForConfirmation(context.Background(), &options{algodClient: algod.client(t), indexerClient: idx.client(t)}, "tx-1-10", 4)
+	if err != nil {
+		t.Errorf("waitForConfirmation() failed with error: %s, want confirmation from the indexer", err)
+	} else if info.ConfirmedRound != 10 {
+		t.Errorf("waitForConfirmation() confirmed round = %d, want 10", info.ConfirmedRound)
+	}
+
+	if _, err := waitForConfirmation(context.Background(), &options{algodClient: algod.client(t)}, "tx-1-10", 4); err == nil {
+		t.Errorf("waitForConfirmation() without indexer succeeded, want error")
+	}
+
+	if _, err := waitForConfirmation(context.Background(), &options{algodClient: algod.client(t), indexerClient: idx.client(t)}, "tx-unknown", 4); err == nil {
+		t.Errorf("waitForConfirmation() for unknown transaction succeeded, want error")
+	}
+}
+
+func TestMetadataToMap(t *testing.T) {
+	meta := &Metadata{
+		Standard:   "arc69",
+		MediaURL:   "ipfs://media",
+		Properties: map[string]interface{}{"b": map[string]interface{}{"bb": "bbb"}},
+		Attributes: []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+
+	got, err := meta.ToMap()
+	if err != nil {
+		t.Fatalf("ToMap() failed with error: %s, want success", err)
+	}
+
+	want := map[string]interface{}{
+		"standard":     "arc69",
+		"description":  "",
+		"external_url": "",
+		"media_url":    "ipfs://media",
+		"mime_type":    "",
+		"properties":   map[string]interface{}{"b": map[string]interface{}{"bb": "bbb"}},
+		"attributes":   []interface{}{map[string]interface{}{"trait_type": "Background", "value": "Blue"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ToMap() = %v, want %v", got, want)
+	}
+}
+
+func TestMetadataSizeBreakdown(t *testing.T) {
+	meta := &Metadata{
+		Standard:    "arc69",
+		Description: "short",
+		Properties:  map[string]interface{}{"lore": strings.Repeat("x", 500)},
+		Attributes:  []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+
+	got := meta.SizeBreakdown()
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+
+	total := 2 + len(got) - 1
+	largest := ""
+	for field, size := range got {
+		total += size
+		if largest == "" || size > got[largest] {
+			largest = field
+		}
+	}
+	if total != len(data) {
+		t.Errorf("SizeBreakdown() = %v sums to %d bytes, want %d", got, total, len(data))
+	}
+	if largest != "properties" {
+		t.Errorf("SizeBreakdown() = %v has %q as the largest field, want properties", got, largest)
+	}
+	if want := len(`"description":"short"`); got["description"] != want {
+		t.Errorf("SizeBreakdown()[description] = %d, want %d", got["description"], want)
+	}
+}
+
+func TestMetadataJSON(t *testing.T) {
+	meta := &Metadata{
+		Standard:    "arc69",
+		Description: "pretty",
+		Properties:  map[string]interface{}{"b": map[string]interface{}{"bb": "bbb"}},
+		Attributes:  []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+
+	compact, err := meta.JSON()
+	if err != nil {
+		t.Fatalf("JSON() failed with error: %s, want success", err)
+	}
+	want := `{"standard":"arc69","description":"pretty","external_url":"","media_url":"","properties":{"b":{"bb":"bbb"}},"mime_type":"","attributes":[{"trait_type":"Background","value":"Blue"}]}`
+	if string(compact) != want {
+		t.Errorf("JSON() = %s, want %s", compact, want)
+	}
+
+	indented, err := meta.JSONIndent()
+	if err != nil {
+		t.Fatalf("JSONIndent() failed with error: %s, want success", err)
+	}
+	if !strings.Contains(string(indented), "\n  \"standard\": \"arc69\"") {
+		t.Errorf("JSONIndent() = %s, want indented output", indented)
+	}
+	var got Metadata
+	if err := json.Unmarshal(indented, &got); err != nil {
+		t.Fatalf("json.Unmarshal() of JSONIndent() failed with error: %s", err)
+	}
+	if !got.Equal(meta) {
+		t.Errorf("json.Unmarshal() of JSONIndent() = %+v, want %+v", got, meta)
+	}
+}
+
+func TestMetadataRoundTripsCleanly(t *testing.T) {
+	clean := &Metadata{
+		Standard:    "ARC69",
+		Description: "clean",
+		Properties: map[string]interface{}{
+			"count":  3,
+			"ratio":  0.5,
+			"tags":   []interface{}{"a", json.Number("1"), true, nil},
+			"nested": map[string]interface{}{"b": "bb"},
+		},
+		Attributes: []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+	if ok, err := clean.RoundTripsCleanly(); !ok || err != nil {
+		t.Errorf("RoundTripsCleanly() = %t, %v, want true, nil", ok, err)
+	}
+
+	lossy := &Metadata{
+		Standard:    "arc69",
+		Description: "lossy\xff",
+		Properties: map[string]interface{}{
+			"created": time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+			"name":    "ok",
+		},
+	}
+	ok, err := lossy.RoundTripsCleanly()
+	if ok || err == nil {
+		t.Fatalf("RoundTripsCleanly() = %t, %v, want false and discrepancies", ok, err)
+	}
+	for _, want := range []string{"description", "properties.created"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("RoundTripsCleanly() error = %q, want it to name %s", err, want)
+		}
+	}
+	if strings.Contains(err.Error(), "properties.name") {
+		t.Errorf("RoundTripsCleanly() error = %q, want properties.name omitted", err)
+	}
+}
+
+func TestFetchLatestOnly(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(1, 11, []byte("gm"))
+	a := New(nil, idx.client(t))
+
+	got, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if got.Description != "v1" {
+		t.Errorf("Fetch() = %q, want the older valid \"v1\"", got.Description)
+	}
+
+	if _, err := a.Fetch(context.Background(), 1, WithLatestOnly()); err == nil || !strings.Contains(err.Error(), "latest note") {
+		t.Errorf("Fetch(WithLatestOnly()) = %v, want error about the latest note", err)
+	}
+}
+
+func TestMetadataSetProperty(t *testing.T) {
+	meta := &Metadata{}
+	if err := meta.SetProperty("a.b.c", "abc"); err != nil {
+		t.Fatalf("SetProperty() failed with error: %s, want success", err)
+	}
+	if err := meta.SetProperty("d", 4); err != nil {
+		t.Fatalf("SetProperty() failed with error: %s, want success", err)
+	}
+
+	want := map[string]interface{}{
+		"a": map[string]interface{}{"b": map[string]interface{}{"c": "abc"}},
+		"d": 4,
+	}
+	if !reflect.DeepEqual(meta.Properties, want) {
+		t.Errorf("SetProperty() = %v, want %v", meta.Properties, want)
+	}
+
+	err := meta.SetProperty("d.e", "de")
+	if want := "unable to set property d.e: property d is not a map"; err == nil || err.Error() != want {
+		t.Errorf("SetProperty() got error: %v, want error: %s", err, want)
+	}
+}
+
+func TestNewOffline(t *testing.T) {
+	a := NewOffline()
+
+	if _, err := a.Fetch(context.Background(), 1); err != ErrMissingClient {
+		t.Errorf("Fetch() = %v, want %v", err, ErrMissingClient)
+	}
+	if _, err := a.BuildUpdate(context.Background(), "", 1, &Metadata{Standard: "arc69"}); err != ErrMissingClient {
+		t.Errorf("BuildUpdate() = %v, want %v", err, ErrMissingClient)
+	}
+
+	meta := &Metadata{Standard: "arc69"}
+	if err := meta.SetProperty("a.b", "ab"); err != nil {
+		t.Fatalf("SetProperty() failed with error: %s, want success", err)
+	}
+	checkProperty("a.b", "ab", meta, t)
+	if err := meta.Validate(); err != nil {
+		t.Errorf("Validate() failed with error: %s, want success", err)
+	}
+}
+
+func TestMetadataPropertyFold(t *testing.T) {
+	meta := &Metadata{Properties: map[string]interface{}{
+		"background": map[string]interface{}{"Color": "blue"},
+		"hat":        "cap",
+		"Hat":        "crown",
+		"eyes":       "green",
+		"EYES":       "red",
+	}}
+
+	got, err := meta.PropertyFold("Background.color")
+	if err != nil {
+		t.Fatalf("PropertyFold() failed with error: %s, want success", err)
+	}
+	if got != "blue" {
+		t.Errorf("PropertyFold(\"Background.color\") = %v, want blue", got)
+	}
+
+	if got, err := meta.PropertyFold("Hat"); err != nil || got != "crown" {
+		t.Errorf("PropertyFold(\"Hat\") = %v, %v, want the exact match crown", got, err)
+	}
+
+	_, err = meta.PropertyFold("Eyes")
+	want := `unable to get property Eyes: Eyes is ambiguous between keys ["EYES" "eyes"]`
+	if err == nil || err.Error() != want {
+		t.Errorf("PropertyFold(\"Eyes\") got error: %v, want error: %s", err, want)
+	}
+}
+
+func TestMetadataPropertyNumbers(t *testing.T) {
+	meta, err := ParseMetadataBytes([]byte(`{"standard":"arc69","properties":{"supply":9007199254740993,"ratio":0.25,"name":"x"}}`))
+	if err != nil {
+		t.Fatalf("ParseMetadataBytes() failed with error: %s, want success", err)
+	}
+
+	got, err := meta.PropertyInt("supply")
+	if err != nil {
+		t.Fatalf("PropertyInt() failed with error: %s, want success", err)
+	}
+	if got != 9007199254740993 {
+		t.Errorf("PropertyInt(\"supply\") = %d, want 9007199254740993", got)
+	}
+
+	if got, err := meta.PropertyFloat("ratio"); err != nil || got != 0.25 {
+		t.Errorf("PropertyFloat(\"ratio\") = %v, %v, want 0.25", got, err)
+	}
+	if _, err := meta.PropertyInt("ratio"); err == nil {
+		t.Errorf("PropertyInt(\"ratio\") succeeded, want error")
+	}
+	if _, err := meta.PropertyFloat("name"); err == nil {
+		t.Errorf("PropertyFloat(\"name\") succeeded, want error")
+	}
+
+	local := &Metadata{Properties: map[string]interface{}{"level": 3}}
+	if got, err := local.PropertyInt("level"); err != nil || got != 3 {
+		t.Errorf("PropertyInt(\"level\") = %d, %v, want 3", got, err)
+	}
 }
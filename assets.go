@@ -0,0 +1,122 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// FetchAssetsParams returns the params of each of the given assets keyed by asset
+// ID. The indexer cannot search assets by a list of IDs, but the assets of a
+// collection usually share a creator, so the first asset not yet found is looked
+// up on its own and the params of every other requested asset of its creator are
+// then read from a search of that creator's assets, paging as needed. If asset
+// searches are not available, the remaining assets are looked up one at a time.
+func (a *ARC69) FetchAssetsParams(ctx context.Context, assetIDs []uint64, opts ...Option) (map[uint64]models.AssetParams, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, ErrMissingClient
+	}
+
+	wanted := make(map[uint64]bool, len(assetIDs))
+	for _, id := range assetIDs {
+		wanted[id] = true
+	}
+
+	params := make(map[uint64]models.AssetParams, len(wanted))
+	searchable := true
+	for _, id := range assetIDs {
+		if _, ok := params[id]; ok {
+			continue
+		}
+
+		var asset models.Asset
+		err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+			_, asset, err = cfg.indexerClient.LookupAssetByID(id).Do(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch asset %d: %s", id, err)
+		}
+		params[id] = asset.Params
+
+		if searchable {
+			err := a.searchCreatorAssets(ctx, cfg, asset.Params.Creator, "", func(asset models.Asset) {
+				if wanted[asset.Index] {
+					params[asset.Index] = asset.Params
+				}
+			})
+			if err != nil {
+				searchable = false
+			}
+		}
+	}
+
+	return params, nil
+}
+
+// FetchByUnitName resolves the asset created by creator whose unit name is
+// unitName and fetches its metadata, returning it along with the asset ID. Unit
+// names are matched exactly. An error is returned if none or several of the
+// creator's assets have that unit name.
+func (a *ARC69) FetchByUnitName(ctx context.Context, creator, unitName string, opts ...Option) (*Metadata, uint64, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, 0, ErrMissingClient
+	}
+
+	var matches []uint64
+	err := a.searchCreatorAssets(ctx, cfg, creator, unitName, func(asset models.Asset) {
+		if asset.Params.UnitName == unitName {
+			matches = append(matches, asset.Index)
+		}
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to search assets of %s: %s", creator, err)
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, 0, fmt.Errorf("no asset of %s has unit name %q", creator, unitName)
+	case 1:
+	default:
+		return nil, 0, fmt.Errorf("assets %v of %s all have unit name %q", matches, creator, unitName)
+	}
+
+	meta, err := a.Fetch(ctx, matches[0], opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return meta, matches[0], nil
+}
+
+// searchCreatorAssets calls visit with each asset created by creator, paging
+// through the indexer. If unit is not empty, the search is narrowed to assets with
+// that unit name.
+func (a *ARC69) searchCreatorAssets(ctx context.Context, cfg *options, creator, unit string, visit func(models.Asset)) error {
+	next := ""
+	for {
+		var resp models.AssetsResponse
+		err := observe(ctx, cfg, "indexer.SearchForAssets", func(ctx context.Context) (err error) {
+			query := cfg.indexerClient.SearchForAssets().Creator(creator).NextToken(next)
+			if unit != "" {
+				query = query.Unit(unit)
+			}
+			resp, err = query.Do(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, asset := range resp.Assets {
+			visit(asset)
+		}
+
+		if resp.NextToken == "" || len(resp.Assets) == 0 {
+			return nil
+		}
+		next = resp.NextToken
+	}
+}
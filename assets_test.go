@@ -0,0 +1,79 @@
+package arc69
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+func TestFetchAssetsParams(t *testing.T) {
+	for _, noAssetSearch := range []bool{false, true} {
+		idx := newFakeIndexer(t)
+		idx.noAssetSearch = noAssetSearch
+		ids := []uint64{1, 2, 3, 4, 5, 6}
+		for _, id := range ids[:5] {
+			idx.addAsset(id, models.AssetParams{Creator: "ALICE", UnitName: "A"})
+		}
+		idx.addAsset(6, models.AssetParams{Creator: "BOB", UnitName: "B"})
+		idx.addAsset(7, models.AssetParams{Creator: "ALICE", UnitName: "A"})
+		a := New(nil, idx.client(t))
+
+		got, err := a.FetchAssetsParams(context.Background(), ids)
+		if err != nil {
+			t.Fatalf("FetchAssetsParams() failed with error: %s, want success", err)
+		}
+		if len(got) != len(ids) {
+			t.Errorf("FetchAssetsParams() returned %d assets, want %d", len(got), len(ids))
+		}
+		for _, id := range ids {
+			if !reflect.DeepEqual(got[id], idx.assets[id].Params) {
+				t.Errorf("FetchAssetsParams()[%d] = %+v, want %+v", id, got[id], idx.assets[id].Params)
+			}
+		}
+
+		var calls int
+		for _, req := range idx.requests {
+			if strings.HasPrefix(req.Path, "/v2/assets") {
+				calls++
+			}
+		}
+		if noAssetSearch {
+			// One failed search, then a lookup per asset.
+			if want := len(ids) + 1; calls != want {
+				t.Errorf("FetchAssetsParams() without asset search made %d indexer calls, want %d", calls, want)
+			}
+		} else if calls >= len(ids) {
+			t.Errorf("FetchAssetsParams() made %d indexer calls for %d assets, want fewer", calls, len(ids))
+		}
+	}
+}
+
+func TestFetchByUnitName(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addAsset(1, models.AssetParams{Creator: "ALICE", UnitName: "CAT1"})
+	idx.addAsset(2, models.AssetParams{Creator: "ALICE", UnitName: "CAT2"})
+	idx.addAsset(3, models.AssetParams{Creator: "ALICE", UnitName: "DUP"})
+	idx.addAsset(4, models.AssetParams{Creator: "ALICE", UnitName: "DUP"})
+	idx.addAsset(5, models.AssetParams{Creator: "BOB", UnitName: "CAT1"})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Description: "two"})
+	idx.addMetadata(t, 5, 10, &Metadata{Standard: "arc69", Description: "bob"})
+	a := New(nil, idx.client(t))
+
+	meta, id, err := a.FetchByUnitName(context.Background(), "ALICE", "CAT2")
+	if err != nil {
+		t.Fatalf("FetchByUnitName() failed with error: %s, want success", err)
+	}
+	if id != 2 || meta.Description != "two" {
+		t.Errorf("FetchByUnitName() = %+v, %d, want metadata of asset 2", meta, id)
+	}
+
+	if _, _, err := a.FetchByUnitName(context.Background(), "ALICE", "DUP"); err == nil || !strings.Contains(err.Error(), "[3 4]") {
+		t.Errorf("FetchByUnitName() of ambiguous unit name = %v, want error naming assets 3 and 4", err)
+	}
+	if _, _, err := a.FetchByUnitName(context.Background(), "BOB", "CAT2"); err == nil {
+		t.Errorf("FetchByUnitName() of unit name of another creator succeeded, want error")
+	}
+}
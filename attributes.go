@@ -0,0 +1,324 @@
+package arc69
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DisplayAttribute is an Attribute classified for display.
+type DisplayAttribute struct {
+	TraitType   string
+	Value       string
+	DisplayType string
+	// Numeric reports whether Value is a number, in which case Number holds it.
+	Numeric bool
+	Number  float64
+}
+
+// DisplayAttributes returns the attributes of m classified as numeric or string
+// values, carrying over their display types.
+func (m *Metadata) DisplayAttributes() []DisplayAttribute {
+	attrs := make([]DisplayAttribute, 0, len(m.Attributes))
+	for _, attr := range m.Attributes {
+		display := DisplayAttribute{
+			TraitType:   attr.TraitType,
+			Value:       attr.ValueString(),
+			DisplayType: attr.DisplayType,
+		}
+		if f, err := strconv.ParseFloat(attr.ValueString(), 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			display.Numeric = true
+			display.Number = f
+		}
+		attrs = append(attrs, display)
+	}
+	return attrs
+}
+
+// AllTraits returns the traits of m gathered from the places ARC69 producers have
+// stored them, in order of precedence: (1) the Attributes array, (2)
+// properties.traits, either as a map of trait types to values or as an array of
+// objects with trait_type and value keys, and (3) the other top-level properties
+// holding a string, number or boolean, taken as traits in key order. Traits are
+// deduped by trait type, compared case-insensitively and ignoring surrounding
+// whitespace, keeping the first one found. Nested maps other than
+// properties.traits and arrays are not read. m is left unchanged.
+func (m *Metadata) AllTraits() []Attribute {
+	var traits []Attribute
+	seen := make(map[string]bool)
+	// Helper function that adds a trait unless its trait type was already seen.
+	add := func(attr Attribute) {
+		key := strings.ToLower(strings.TrimSpace(attr.TraitType))
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		traits = append(traits, attr)
+	}
+
+	for _, attr := range m.Attributes {
+		add(attr)
+	}
+
+	switch nested := m.Properties["traits"].(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(nested))
+		for k := range nested {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if value, ok := traitValue(nested[k]); ok {
+				add(Attribute{TraitType: k, Value: value})
+			}
+		}
+	case []interface{}:
+		for _, elem := range nested {
+			obj, ok := elem.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			trait, _ := obj["trait_type"].(string)
+			if value, ok := traitValue(obj["value"]); ok {
+				add(Attribute{TraitType: trait, Value: value})
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(m.Properties))
+	for k := range m.Properties {
+		if k != "traits" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if value, ok := traitValue(m.Properties[k]); ok {
+			add(Attribute{TraitType: k, Value: value})
+		}
+	}
+
+	return traits
+}
+
+// Helper function that returns a property value as a trait value, reporting false
+// for values other than strings, numbers and booleans. Go numbers are converted to
+// json.Number like numbers decoded from notes.
+func traitValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case string, json.Number, bool:
+		return v, true
+	case float64, int, int64:
+		return normalizeNumbers(v), true
+	default:
+		return nil, false
+	}
+}
+
+// SetAttribute sets the value of the attribute with the given trait type, or adds
+// it to the end of the attributes if m has none. When several attributes share
+// the trait type, only the first one is updated.
+func (m *Metadata) SetAttribute(trait, value string) {
+	for i := range m.Attributes {
+		if m.Attributes[i].TraitType == trait {
+			m.Attributes[i].Value = value
+			return
+		}
+	}
+	m.Attributes = append(m.Attributes, Attribute{TraitType: trait, Value: value})
+}
+
+// RemoveAttribute removes the first attribute with the given trait type and
+// reports whether one was found.
+func (m *Metadata) RemoveAttribute(trait string) bool {
+	for i := range m.Attributes {
+		if m.Attributes[i].TraitType == trait {
+			m.Attributes = append(m.Attributes[:i], m.Attributes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SortedAttributes returns a copy of the attributes of m sorted by trait type and
+// then by value. m is left unchanged.
+func (m *Metadata) SortedAttributes() []Attribute {
+	attrs := append([]Attribute(nil), m.Attributes...)
+	sort.SliceStable(attrs, func(i, j int) bool {
+		if attrs[i].TraitType != attrs[j].TraitType {
+			return attrs[i].TraitType < attrs[j].TraitType
+		}
+		return attrs[i].ValueString() < attrs[j].ValueString()
+	})
+	return attrs
+}
+
+// marketplaceProfile holds the attribute constraints of a marketplace.
+type marketplaceProfile struct {
+	// displayTypes holds the supported display types, mapped to whether they
+	// require a numeric value.
+	displayTypes map[string]bool
+	// sorted requires attributes to be ordered as by SortedAttributes.
+	sorted bool
+}
+
+// marketplaceProfiles holds the profiles supported by NormalizeForMarketplace.
+var marketplaceProfiles = map[string]marketplaceProfile{
+	// opensea follows the display types of the OpenSea metadata standard, which
+	// many marketplaces render.
+	"opensea": {
+		displayTypes: map[string]bool{
+			"number":           true,
+			"boost_number":     true,
+			"boost_percentage": true,
+			"date":             true,
+		},
+		sorted: true,
+	},
+}
+
+// NormalizeForMarketplace adjusts the attributes of m in place to the constraints
+// of the named marketplace profile and returns a description of each adjustment.
+// Display types the profile does not support, and numeric display types set on
+// non-numeric values, are cleared, then the attributes are sorted if the profile
+// requires a stable order. The only profile is "opensea". An error is returned
+// for an unknown profile, in which case m is left unchanged.
+func (m *Metadata) NormalizeForMarketplace(name string) ([]string, error) {
+	profile, ok := marketplaceProfiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown marketplace profile: %s", name)
+	}
+
+	var adjustments []string
+	for i := range m.Attributes {
+		attr := &m.Attributes[i]
+		if attr.DisplayType == "" {
+			continue
+		}
+
+		numeric, supported := profile.displayTypes[attr.DisplayType]
+		if !supported {
+			adjustments = append(adjustments, fmt.Sprintf("%s: removed unsupported display type %q", attr.TraitType, attr.DisplayType))
+			attr.DisplayType = ""
+			continue
+		}
+		if _, err := strconv.ParseFloat(attr.ValueString(), 64); numeric && err != nil {
+			adjustments = append(adjustments, fmt.Sprintf("%s: removed display type %q from non-numeric value %q", attr.TraitType, attr.DisplayType, attr.ValueString()))
+			attr.DisplayType = ""
+		}
+	}
+
+	if profile.sorted && len(m.Attributes) > 1 {
+		sorted := m.SortedAttributes()
+		if !reflect.DeepEqual(sorted, m.Attributes) {
+			m.Attributes = sorted
+			adjustments = append(adjustments, "attributes: reordered by trait type and value")
+		}
+	}
+
+	return adjustments, nil
+}
+
+// NormalizeFlag selects a change made by Normalize. Flags can be combined with |.
+type NormalizeFlag int
+
+const (
+	// NormalizeTraitTypes lowercases trait types and trims their surrounding whitespace.
+	NormalizeTraitTypes NormalizeFlag = 1 << iota
+	// TrimValues trims the whitespace surrounding trait values.
+	TrimValues
+	// CoerceNumbers rewrites numeric trait values in their shortest decimal form,
+	// so "03", "3.0" and "3" all become "3". Non-numeric values are left untouched.
+	CoerceNumbers
+)
+
+// Normalize canonicalizes the attributes of m in place so that attributes from
+// different producers can be compared. Only the changes selected by flags are
+// made and no attribute is ever added or removed.
+func (m *Metadata) Normalize(flags NormalizeFlag) {
+	for i := range m.Attributes {
+		attr := &m.Attributes[i]
+		if flags&NormalizeTraitTypes != 0 {
+			attr.TraitType = strings.ToLower(strings.TrimSpace(attr.TraitType))
+		}
+		if s, ok := attr.Value.(string); ok && flags&TrimValues != 0 {
+			attr.Value = strings.TrimSpace(s)
+		}
+		if flags&CoerceNumbers != 0 {
+			switch v := attr.Value.(type) {
+			case string:
+				attr.Value = coerceNumber(v)
+			case json.Number:
+				attr.Value = json.Number(coerceNumber(v.String()))
+			}
+		}
+	}
+}
+
+// MergePolicy decides how MergeAttributes resolves attributes sharing a trait type.
+type MergePolicy int
+
+const (
+	// KeepExisting keeps the current value of a trait type and only adds the
+	// trait types that are not yet present.
+	KeepExisting MergePolicy = iota
+	// Overwrite replaces the current value of a trait type with the merged one,
+	// adding the trait types that are not yet present.
+	Overwrite
+	// Append adds every merged attribute, allowing duplicate trait types.
+	Append
+)
+
+// MergeAttributes merges other into the attributes of m in place. KeepExisting
+// and Overwrite dedupe by trait type, so a trait type present in both ends up
+// once with the value chosen by the policy, while Append keeps duplicates.
+// Existing attributes keep their position and new ones are added in order.
+func (m *Metadata) MergeAttributes(other []Attribute, policy MergePolicy) {
+	if policy == Append {
+		m.Attributes = append(m.Attributes, other...)
+		return
+	}
+
+	positions := make(map[string]int, len(m.Attributes))
+	for i, attr := range m.Attributes {
+		if _, ok := positions[attr.TraitType]; !ok {
+			positions[attr.TraitType] = i
+		}
+	}
+
+	for _, attr := range other {
+		i, ok := positions[attr.TraitType]
+		switch {
+		case !ok:
+			positions[attr.TraitType] = len(m.Attributes)
+			m.Attributes = append(m.Attributes, attr)
+		case policy == Overwrite:
+			m.Attributes[i] = attr
+		}
+	}
+}
+
+// Helper function that rewrites a numeric string in its shortest decimal form.
+// Integers, including those written with a fraction or exponent such as "3.0",
+// are kept exact like in normalizeNumbers; only other decimals go through float64.
+func coerceNumber(v string) string {
+	if !strings.ContainsAny(v, ".eE") {
+		if i, ok := new(big.Int).SetString(v, 10); ok {
+			return i.String()
+		}
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return v
+	}
+	if r, ok := new(big.Rat).SetString(v); ok && r.IsInt() {
+		return r.Num().String()
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,295 @@
+package arc69
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMetadataNormalize(t *testing.T) {
+	newMeta := func() *Metadata {
+		return &Metadata{Attributes: []Attribute{
+			{TraitType: " Level ", Value: " 03 "},
+			{TraitType: "Background", Value: " Deep Blue "},
+			{TraitType: "Speed", Value: "1.50"},
+		}}
+	}
+
+	meta := newMeta()
+	meta.Normalize(NormalizeTraitTypes | TrimValues)
+	want := []Attribute{
+		{TraitType: "level", Value: "03"},
+		{TraitType: "background", Value: "Deep Blue"},
+		{TraitType: "speed", Value: "1.50"},
+	}
+	if !reflect.DeepEqual(meta.Attributes, want) {
+		t.Errorf("Normalize(NormalizeTraitTypes|TrimValues) = %+v, want %+v", meta.Attributes, want)
+	}
+
+	meta.Normalize(CoerceNumbers)
+	want[0].Value = "3"
+	want[2].Value = "1.5"
+	if !reflect.DeepEqual(meta.Attributes, want) {
+		t.Errorf("Normalize(CoerceNumbers) = %+v, want %+v", meta.Attributes, want)
+	}
+
+	meta = &Metadata{Attributes: []Attribute{
+		{TraitType: "serial", Value: "0123456789012345678901234"},
+		{TraitType: "whole", Value: "12345678901234567890.000"},
+		{TraitType: "exponent", Value: "1.5e2"},
+		{TraitType: "name", Value: "1st"},
+	}}
+	meta.Normalize(CoerceNumbers)
+	want = []Attribute{
+		{TraitType: "serial", Value: "123456789012345678901234"},
+		{TraitType: "whole", Value: "12345678901234567890"},
+		{TraitType: "exponent", Value: "150"},
+		{TraitType: "name", Value: "1st"},
+	}
+	if !reflect.DeepEqual(meta.Attributes, want) {
+		t.Errorf("Normalize(CoerceNumbers) of values above 2^53 = %+v, want %+v", meta.Attributes, want)
+	}
+
+	meta = newMeta()
+	meta.Normalize(0)
+	if !reflect.DeepEqual(meta, newMeta()) {
+		t.Errorf("Normalize(0) = %+v, want unchanged attributes", meta.Attributes)
+	}
+}
+
+func TestMetadataDisplayAttributes(t *testing.T) {
+	meta := &Metadata{Attributes: []Attribute{
+		{TraitType: "Level", Value: "3", DisplayType: "number"},
+		{TraitType: "Background", Value: "Blue"},
+	}}
+
+	got := meta.DisplayAttributes()
+	want := []DisplayAttribute{
+		{TraitType: "Level", Value: "3", DisplayType: "number", Numeric: true, Number: 3},
+		{TraitType: "Background", Value: "Blue"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DisplayAttributes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAttributeJSON(t *testing.T) {
+	attrs := []Attribute{
+		{TraitType: "Level", Value: "3", DisplayType: "boost_number"},
+		{TraitType: "Background", Value: "Blue"},
+	}
+
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+	want := `[{"trait_type":"Level","value":"3","display_type":"boost_number"},{"trait_type":"Background","value":"Blue"}]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+
+	var got []Attribute
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("json.Unmarshal() failed with error: %s", err)
+	}
+	if !reflect.DeepEqual(got, attrs) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", got, attrs)
+	}
+
+	if err := json.Unmarshal([]byte(`[{"trait_type":"Level","value":3},{"trait_type":"Rare","value":true}]`), &got); err != nil {
+		t.Fatalf("json.Unmarshal() of non-string values failed with error: %s", err)
+	}
+	want2 := []Attribute{{TraitType: "Level", Value: json.Number("3")}, {TraitType: "Rare", Value: true}}
+	if !reflect.DeepEqual(got, want2) {
+		t.Errorf("json.Unmarshal() = %+v, want %+v", got, want2)
+	}
+	if got[0].ValueString() != "3" || got[1].ValueString() != "true" {
+		t.Errorf("ValueString() = %q, %q, want \"3\", \"true\"", got[0].ValueString(), got[1].ValueString())
+	}
+
+	data, err = json.Marshal(got)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+	if want := `[{"trait_type":"Level","value":3},{"trait_type":"Rare","value":true}]`; string(data) != want {
+		t.Errorf("json.Marshal() of decoded non-string values = %s, want %s", data, want)
+	}
+}
+
+func TestMergeAttributes(t *testing.T) {
+	existing := []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+	}
+	other := []Attribute{
+		{TraitType: "Hat", Value: "Crown"},
+		{TraitType: "Eyes", Value: "Green"},
+	}
+
+	tests := []struct {
+		policy MergePolicy
+		want   []Attribute
+	}{
+		{KeepExisting, []Attribute{
+			{TraitType: "Background", Value: "Blue"},
+			{TraitType: "Hat", Value: "Cap"},
+			{TraitType: "Eyes", Value: "Green"},
+		}},
+		{Overwrite, []Attribute{
+			{TraitType: "Background", Value: "Blue"},
+			{TraitType: "Hat", Value: "Crown"},
+			{TraitType: "Eyes", Value: "Green"},
+		}},
+		{Append, []Attribute{
+			{TraitType: "Background", Value: "Blue"},
+			{TraitType: "Hat", Value: "Cap"},
+			{TraitType: "Hat", Value: "Crown"},
+			{TraitType: "Eyes", Value: "Green"},
+		}},
+	}
+	for _, test := range tests {
+		m := &Metadata{Attributes: append([]Attribute(nil), existing...)}
+		m.MergeAttributes(other, test.policy)
+		if !reflect.DeepEqual(m.Attributes, test.want) {
+			t.Errorf("MergeAttributes(%d) = %+v, want %+v", test.policy, m.Attributes, test.want)
+		}
+	}
+}
+
+func TestMetadataSortedAttributes(t *testing.T) {
+	m := &Metadata{Attributes: []Attribute{
+		{TraitType: "Hat", Value: "Crown"},
+		{TraitType: "Background", Value: "Red"},
+		{TraitType: "Hat", Value: "Cap"},
+		{TraitType: "Background", Value: "Blue"},
+	}}
+	original := append([]Attribute(nil), m.Attributes...)
+
+	got := m.SortedAttributes()
+	want := []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Background", Value: "Red"},
+		{TraitType: "Hat", Value: "Cap"},
+		{TraitType: "Hat", Value: "Crown"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedAttributes() = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(m.Attributes, original) {
+		t.Errorf("SortedAttributes() changed the receiver to %+v, want %+v", m.Attributes, original)
+	}
+}
+
+func TestMetadataSetAttribute(t *testing.T) {
+	m := &Metadata{Attributes: []Attribute{
+		{TraitType: "Hat", Value: "Cap", DisplayType: "string"},
+		{TraitType: "Hat", Value: "Crown"},
+	}}
+
+	m.SetAttribute("Hat", "Beanie")
+	m.SetAttribute("Eyes", "Green")
+	want := []Attribute{
+		{TraitType: "Hat", Value: "Beanie", DisplayType: "string"},
+		{TraitType: "Hat", Value: "Crown"},
+		{TraitType: "Eyes", Value: "Green"},
+	}
+	if !reflect.DeepEqual(m.Attributes, want) {
+		t.Errorf("SetAttribute() = %+v, want %+v", m.Attributes, want)
+	}
+}
+
+func TestMetadataRemoveAttribute(t *testing.T) {
+	m := &Metadata{Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+	}}
+
+	if !m.RemoveAttribute("Background") {
+		t.Errorf("RemoveAttribute(\"Background\") = false, want true")
+	}
+	if m.RemoveAttribute("Eyes") {
+		t.Errorf("RemoveAttribute(\"Eyes\") = true, want false")
+	}
+	if want := []Attribute{{TraitType: "Hat", Value: "Cap"}}; !reflect.DeepEqual(m.Attributes, want) {
+		t.Errorf("RemoveAttribute() left %+v, want %+v", m.Attributes, want)
+	}
+}
+
+func TestMetadataNormalizeForMarketplace(t *testing.T) {
+	meta := &Metadata{Attributes: []Attribute{
+		{TraitType: "Power", Value: "10", DisplayType: "boost_number"},
+		{TraitType: "Color", Value: "Red", DisplayType: "swatch"},
+		{TraitType: "Birthday", Value: "soon", DisplayType: "date"},
+	}}
+
+	got, err := meta.NormalizeForMarketplace("opensea")
+	if err != nil {
+		t.Fatalf("NormalizeForMarketplace() failed with error: %s, want success", err)
+	}
+	wantAdjustments := []string{
+		`Color: removed unsupported display type "swatch"`,
+		`Birthday: removed display type "date" from non-numeric value "soon"`,
+		"attributes: reordered by trait type and value",
+	}
+	if !reflect.DeepEqual(got, wantAdjustments) {
+		t.Errorf("NormalizeForMarketplace() = %q, want %q", got, wantAdjustments)
+	}
+	wantAttrs := []Attribute{
+		{TraitType: "Birthday", Value: "soon"},
+		{TraitType: "Color", Value: "Red"},
+		{TraitType: "Power", Value: "10", DisplayType: "boost_number"},
+	}
+	if !reflect.DeepEqual(meta.Attributes, wantAttrs) {
+		t.Errorf("NormalizeForMarketplace() attributes = %+v, want %+v", meta.Attributes, wantAttrs)
+	}
+
+	if got, err := meta.NormalizeForMarketplace("opensea"); err != nil || len(got) != 0 {
+		t.Errorf("NormalizeForMarketplace() of normalized metadata = %q, %v, want no adjustments", got, err)
+	}
+	if _, err := meta.NormalizeForMarketplace("unknown"); err == nil {
+		t.Errorf("NormalizeForMarketplace(\"unknown\") succeeded, want error")
+	}
+}
+
+func TestMetadataAllTraits(t *testing.T) {
+	meta := &Metadata{
+		Attributes: []Attribute{{TraitType: "Background", Value: "Blue"}},
+		Properties: map[string]interface{}{
+			"traits": map[string]interface{}{
+				"background": "Red",
+				"Hat":        "Cap",
+				"Stats":      map[string]interface{}{"hp": 10},
+			},
+			"Level":   json.Number("3"),
+			"hat":     "Crown",
+			"Shiny":   true,
+			"Nested":  map[string]interface{}{"Eyes": "Green"},
+			"Tags":    []interface{}{"rare"},
+			"Creator": "alice",
+		},
+	}
+
+	got := meta.AllTraits()
+	want := []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+		{TraitType: "Creator", Value: "alice"},
+		{TraitType: "Level", Value: json.Number("3")},
+		{TraitType: "Shiny", Value: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AllTraits() = %+v, want %+v", got, want)
+	}
+
+	meta = &Metadata{Properties: map[string]interface{}{
+		"traits": []interface{}{
+			map[string]interface{}{"trait_type": "Hat", "value": "Cap"},
+			map[string]interface{}{"trait_type": "Level", "value": json.Number("2")},
+			"junk",
+		},
+	}}
+	want = []Attribute{{TraitType: "Hat", Value: "Cap"}, {TraitType: "Level", Value: json.Number("2")}}
+	if got := meta.AllTraits(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllTraits() with traits array = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package arc69
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// fetchCache is a concurrency-safe LRU cache of Fetch results keyed by asset ID.
+// Every access reorders the LRU list, so all methods take the exclusive lock.
+type fetchCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[uint64]*list.Element
+	// order holds the cache entries from most to least recently used.
+	order *list.List
+	// gen counts the invalidations of any entry, so that a Fetch racing an
+	// invalidation does not cache the metadata it fetched before it. A single
+	// counter keeps the cache bounded, at the cost of also skipping the results
+	// of Fetch calls for other assets racing the invalidation.
+	gen uint64
+}
+
+type cacheEntry struct {
+	assetID uint64
+	meta    *Metadata
+	expires time.Time
+}
+
+func newFetchCache(size int, ttl time.Duration) *fetchCache {
+	return &fetchCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[uint64]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// generation returns the generation of the cache, which grows every time an entry
+// is removed or the cache is cleared.
+func (c *fetchCache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen
+}
+
+// get returns a copy of the cached metadata for an asset if it has not expired.
+func (c *fetchCache) get(assetID uint64) (*Metadata, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[assetID]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, assetID)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.meta.clone(), true
+}
+
+// add caches a copy of the metadata for an asset, evicting the least recently
+// used entry if the cache is full. gen is the generation of the cache when the
+// metadata was fetched; nothing is cached if an entry was invalidated since.
+func (c *fetchCache) add(assetID uint64, meta *Metadata, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		return
+	}
+
+	entry := &cacheEntry{assetID: assetID, meta: meta.clone(), expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[assetID]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[assetID] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).assetID)
+	}
+}
+
+// remove drops the cached metadata for an asset.
+func (c *fetchCache) remove(assetID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	if elem, ok := c.entries[assetID]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, assetID)
+	}
+}
+
+// clear drops every cached entry.
+func (c *fetchCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	c.entries = make(map[uint64]*list.Element)
+	c.order.Init()
+}
+
+// InvalidateCache drops the cached Fetch result for an asset, if any. Update calls
+// it automatically once the new metadata is confirmed.
+func (a *ARC69) InvalidateCache(assetID uint64) {
+	if a.cache != nil {
+		a.cache.remove(assetID)
+	}
+}
@@ -0,0 +1,138 @@
+package arc69
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFetchCache(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t), WithCache(10, time.Minute))
+
+	for i := 0; i < 2; i++ {
+		meta, err := a.Fetch(context.Background(), 1)
+		if err != nil {
+			t.Fatalf("Fetch() failed with error: %s, want success", err)
+		}
+		if meta.Description != "v1" {
+			t.Errorf("Fetch() = %q, want \"v1\"", meta.Description)
+		}
+		meta.Description = "mutated"
+	}
+	if got := len(idx.requestsTo("/v2/assets/1/transactions")); got != 1 {
+		t.Errorf("Fetch() twice made %d indexer requests, want 1", got)
+	}
+
+	if err := a.Update(context.Background(), account, 1, &Metadata{Standard: "arc69", Description: "v2"}); err != nil {
+		t.Fatalf("Update() failed with error: %s, want success", err)
+	}
+
+	meta, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if meta.Description != "v2" {
+		t.Errorf("Fetch() after Update() = %q, want \"v2\"", meta.Description)
+	}
+}
+
+func TestFetchCacheExpiryAndEviction(t *testing.T) {
+	c := newFetchCache(1, time.Millisecond)
+	c.add(1, &Metadata{Description: "one"}, c.generation())
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := c.get(1); ok {
+		t.Errorf("get(1) found an expired entry, want miss")
+	}
+
+	c = newFetchCache(1, 0)
+	c.add(1, &Metadata{Description: "one"}, c.generation())
+	c.add(2, &Metadata{Description: "two"}, c.generation())
+	if _, ok := c.get(1); ok {
+		t.Errorf("get(1) found an evicted entry, want miss")
+	}
+	if meta, ok := c.get(2); !ok || meta.Description != "two" {
+		t.Errorf("get(2) = %+v, %t, want cached entry", meta, ok)
+	}
+}
+
+// invalidatingObserver invalidates the cached metadata of an asset whenever a call
+// named op returns, as a concurrent InvalidateCache would.
+type invalidatingObserver struct {
+	a       *ARC69
+	op      string
+	assetID uint64
+}
+
+func (o *invalidatingObserver) OnRequest(op string) {}
+
+func (o *invalidatingObserver) OnResponse(op string, dur time.Duration, err error) {
+	if op == o.op {
+		o.a.InvalidateCache(o.assetID)
+	}
+}
+
+func TestFetchCacheInvalidatedDuringFetch(t *testing.T) {
+	c := newFetchCache(10, 0)
+	gen := c.generation()
+	c.remove(1)
+	c.add(1, &Metadata{Description: "stale"}, gen)
+	if meta, ok := c.get(1); ok {
+		t.Errorf("get(1) = %+v, want miss for metadata fetched before remove(1)", meta)
+	}
+	gen = c.generation()
+	c.clear()
+	c.add(1, &Metadata{Description: "stale"}, gen)
+	if meta, ok := c.get(1); ok {
+		t.Errorf("get(1) = %+v, want miss for metadata fetched before clear()", meta)
+	}
+
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	obs := &invalidatingObserver{op: "indexer.LookupAssetTransactions", assetID: 1}
+	a := New(nil, idx.client(t), WithCache(10, time.Minute), WithObserver(obs))
+	obs.a = a
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Fetch(context.Background(), 1); err != nil {
+			t.Fatalf("Fetch() failed with error: %s, want success", err)
+		}
+	}
+	if got := len(idx.requestsTo("/v2/assets/1/transactions")); got != 2 {
+		t.Errorf("Fetch() twice with InvalidateCache() during each made %d indexer requests, want 2", got)
+	}
+}
+
+// TestFetchCacheConcurrency is meant to be run with -race.
+func TestFetchCacheConcurrency(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Properties: map[string]interface{}{"a": "aa"}})
+	a := New(nil, idx.client(t), WithCache(1, time.Minute))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				meta, err := a.Fetch(context.Background(), 1)
+				if err != nil {
+					t.Errorf("Fetch() failed with error: %s, want success", err)
+					return
+				}
+				meta.Properties["a"] = "mutated"
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				a.InvalidateCache(1)
+			}
+		}()
+	}
+	wg.Wait()
+}
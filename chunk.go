@@ -0,0 +1,175 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+)
+
+// maxNoteSize is the maximum size of a transaction note in bytes.
+const maxNoteSize = 1024
+
+// gzipOverhead bounds the bytes gzip adds to data that does not compress, which
+// UpdateChunked reserves in each chunk when compressing notes.
+const gzipOverhead = 32
+
+// noteChunk is one of the notes holding a piece of metadata split by UpdateChunked.
+type noteChunk struct {
+	Index int    `json:"i"`
+	Count int    `json:"n"`
+	Data  string `json:"d"`
+}
+
+// UpdateChunked updates the ARC69 metadata of the given asset like Update, except
+// that metadata too large for a single note is split into chunks of the form
+// {"i":0,"n":3,"d":"..."}, each submitted in its own acfg transaction in order
+// of index. Each chunk is stored like a note written by Update, so WithCompression
+// and WithNotePrefix apply to it. Chunked metadata can only be read back with
+// FetchChunked; Fetch skips its chunks like any other note not holding ARC69
+// metadata.
+func (a *ARC69) UpdateChunked(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata, opts ...Option) error {
+	cfg := a.config(opts)
+	if !meta.IsValid() {
+		return fmt.Errorf("invalid metadata")
+	}
+
+	data, err := meta.JSON()
+	if err != nil {
+		return err
+	}
+
+	note, err := encodeNote(data, cfg)
+	if err != nil {
+		return err
+	}
+	if len(note) <= maxNoteSize {
+		return a.Update(ctx, account, assetID, meta, opts...)
+	}
+
+	reserved := len(cfg.notePrefix)
+	if cfg.compressNotes {
+		reserved += gzipOverhead
+	}
+	chunks := splitChunks(string(data), reserved)
+	for i, chunk := range chunks {
+		encoded, err := json.Marshal(noteChunk{Index: i, Count: len(chunks), Data: chunk})
+		if err != nil {
+			return fmt.Errorf("unable to encode chunk %d: %s", i, err)
+		}
+		note, err := encodeNote(encoded, cfg)
+		if err != nil {
+			return fmt.Errorf("unable to encode chunk %d: %s", i, err)
+		}
+		if len(note) > maxNoteSize {
+			return fmt.Errorf("unable to encode chunk %d: %d bytes, more than %d", i, len(note), maxNoteSize)
+		}
+
+		txn, err := a.buildConfigTxn(ctx, cfg, account.Address.String(), assetID, note)
+		if err != nil {
+			return err
+		}
+
+		txID, signedTxn, err := crypto.SignTransaction(account.PrivateKey, txn)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %s", err)
+		}
+
+		if _, err := a.submit(ctx, cfg, txID, signedTxn); err != nil {
+			return fmt.Errorf("unable to submit chunk %d of %d: %s", i, len(chunks), err)
+		}
+	}
+
+	a.InvalidateCache(assetID)
+	return nil
+}
+
+// FetchChunked attempts to retrieve the ARC69 metadata of an asset stored by
+// UpdateChunked. The chunks are expected in the latest acfg notes of the asset,
+// and an error naming the first missing chunk is returned if any is absent. If
+// the latest note is not a chunk, it is parsed as regular metadata. Chunks are
+// read like notes written by Update, so the note prefix is stripped and compressed
+// chunks are decompressed.
+func (a *ARC69) FetchChunked(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, error) {
+	cfg := a.config(opts)
+	notes, err := a.latestNotes(ctx, cfg, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodeNote(notes[0], cfg)
+	if err != nil {
+		return nil, err
+	}
+	if last, ok := parseChunk(data); ok {
+		// The count comes from the note, so the chunks are only collected as they
+		// are found rather than allocated up front.
+		var parts []string
+		for k := 0; k < last.Count; k++ {
+			index := last.Count - 1 - k
+			if k >= len(notes) {
+				return nil, fmt.Errorf("missing chunk %d of %d for asset %d", index, last.Count, assetID)
+			}
+			note, err := decodeNote(notes[k], cfg)
+			var chunk noteChunk
+			if err == nil {
+				chunk, ok = parseChunk(note)
+			}
+			if err != nil || !ok || chunk.Count != last.Count || chunk.Index != index {
+				return nil, fmt.Errorf("missing chunk %d of %d for asset %d", index, last.Count, assetID)
+			}
+			parts = append(parts, chunk.Data)
+		}
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		data = []byte(strings.Join(parts, ""))
+	}
+
+	meta, err := parseMetadata(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsValid() {
+		return nil, fmt.Errorf("invalid metadata for asset %d", assetID)
+	}
+
+	return meta, nil
+}
+
+// Helper function that decodes note as a chunk, reporting whether it is one.
+func parseChunk(note []byte) (noteChunk, bool) {
+	dec := json.NewDecoder(bytes.NewReader(note))
+	dec.DisallowUnknownFields()
+
+	var chunk noteChunk
+	if err := dec.Decode(&chunk); err != nil || chunk.Count <= 0 {
+		return noteChunk{}, false
+	}
+	return chunk, true
+}
+
+// Helper function that splits data into pieces whose chunk encoding fits in a
+// note along with reserved bytes. Pieces are split on rune boundaries, accounting
+// for JSON escaping.
+func splitChunks(data string, reserved int) []string {
+	digits := len(strconv.Itoa(len(data)))
+	budget := maxNoteSize - reserved - len(`{"i":,"n":,"d":""}`) - 2*digits
+
+	var chunks []string
+	start, size := 0, 0
+	for i, r := range data {
+		escaped, _ := json.Marshal(string(r))
+		n := len(escaped) - 2
+		if size+n > budget {
+			chunks = append(chunks, data[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	return append(chunks, data[start:])
+}
@@ -0,0 +1,117 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestUpdateChunkedRoundTrip(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	meta := &Metadata{
+		Standard:    "arc69",
+		Description: strings.Repeat(`a "quoted" <tag> and ünïcödé `, 80),
+		Properties:  map[string]interface{}{"lore": strings.Repeat("x", 500)},
+	}
+	if err := a.UpdateChunked(context.Background(), account, 1, meta); err != nil {
+		t.Fatalf("UpdateChunked() failed with error: %s, want success", err)
+	}
+
+	sent := algod.sentTxns()
+	if len(sent) < 3 {
+		t.Errorf("UpdateChunked() submitted %d transactions, want at least 3", len(sent))
+	}
+	for i, stxn := range sent {
+		if len(stxn.Txn.Note) > maxNoteSize {
+			t.Errorf("UpdateChunked() chunk %d has %d bytes, want at most %d", i, len(stxn.Txn.Note), maxNoteSize)
+		}
+	}
+
+	got, err := a.FetchChunked(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchChunked() failed with error: %s, want success", err)
+	}
+	if !got.Equal(meta) {
+		t.Errorf("FetchChunked() = %+v, want %+v", got, meta)
+	}
+}
+
+func TestUpdateChunkedNoteOptions(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	prefix := []byte("arc69:j")
+	a := New(algod.client(t), idx.client(t), WithNotePrefix(prefix), WithCompression())
+
+	meta := &Metadata{
+		Standard:    "arc69",
+		Description: strings.Repeat("abcdefghijklmnopqrstuvwxyz0123456789", 200),
+	}
+	if err := a.UpdateChunked(context.Background(), account, 1, meta); err != nil {
+		t.Fatalf("UpdateChunked(WithNotePrefix(), WithCompression()) failed with error: %s, want success", err)
+	}
+
+	for i, stxn := range algod.sentTxns() {
+		note := stxn.Txn.Note
+		if len(note) > maxNoteSize {
+			t.Errorf("UpdateChunked() chunk %d has %d bytes, want at most %d", i, len(note), maxNoteSize)
+		}
+		if !bytes.HasPrefix(note, prefix) || !bytes.HasPrefix(note[len(prefix):], gzipMagic) {
+			t.Errorf("UpdateChunked() chunk %d = %q, want prefixed gzip note", i, note)
+		}
+	}
+
+	got, err := a.FetchChunked(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchChunked() failed with error: %s, want success", err)
+	}
+	if !got.Equal(meta) {
+		t.Errorf("FetchChunked() = %+v, want %+v", got, meta)
+	}
+}
+
+func TestFetchChunkedMissingChunk(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 10, []byte(`{"i":0,"n":3,"d":"{\"standard\":"}`))
+	idx.addNote(1, 12, []byte(`{"i":2,"n":3,"d":"}"}`))
+
+	_, err := New(nil, idx.client(t)).FetchChunked(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), "missing chunk 1 of 3") {
+		t.Errorf("FetchChunked() = %v, want error about missing chunk 1 of 3", err)
+	}
+}
+
+func TestFetchChunkedBadCount(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69"})
+	idx.addNote(1, 12, []byte(`{"i":0,"n":100000000000000,"d":""}`))
+
+	_, err := New(nil, idx.client(t)).FetchChunked(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), "missing chunk") {
+		t.Errorf("FetchChunked() with a huge chunk count = %v, want error about a missing chunk", err)
+	}
+
+	idx = newFakeIndexer(t)
+	idx.addNote(1, 10, []byte(`{"i":0,"n":-1,"d":""}`))
+	if _, err := New(nil, idx.client(t)).FetchChunked(context.Background(), 1); err == nil {
+		t.Errorf("FetchChunked() with a negative chunk count succeeded, want error")
+	}
+}
+
+func TestFetchChunkedSingleNote(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "small"})
+
+	got, err := New(nil, idx.client(t)).FetchChunked(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchChunked() failed with error: %s, want success", err)
+	}
+	if got.Description != "small" {
+		t.Errorf("FetchChunked() = %q, want \"small\"", got.Description)
+	}
+}
@@ -0,0 +1,334 @@
+package arc69
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// batchWorkers is the number of assets fetched concurrently by BatchFetch.
+const batchWorkers = 4
+
+// BatchFetch fetches the ARC69 metadata for each of the given assets and returns
+// it keyed by asset ID. Assets are fetched concurrently and the first failure
+// stops the batch and is returned as an error.
+func (a *ARC69) BatchFetch(ctx context.Context, assetIDs []uint64, opts ...Option) (map[uint64]*Metadata, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		results  = make(map[uint64]*Metadata, len(assetIDs))
+		ids      = make(chan uint64)
+	)
+
+	for i := 0; i < batchWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				meta, err := a.Fetch(ctx, id, opts...)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("unable to fetch asset %d: %s", id, err)
+					cancel()
+				} else if err == nil {
+					results[id] = meta
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, id := range assetIDs {
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// CollectionTraits fetches the metadata of the given assets and returns, for each
+// trait type found in their attributes, the number of assets having each value.
+func (a *ARC69) CollectionTraits(ctx context.Context, assetIDs []uint64, opts ...Option) (map[string]map[string]int, error) {
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	traits := make(map[string]map[string]int)
+	for _, meta := range metas {
+		for _, attr := range meta.Attributes {
+			if traits[attr.TraitType] == nil {
+				traits[attr.TraitType] = make(map[string]int)
+			}
+			traits[attr.TraitType][attr.ValueString()]++
+		}
+	}
+
+	return traits, nil
+}
+
+// RarityScores fetches the metadata of the given assets and returns the rarity
+// score of each of them within that collection. The score of an asset is the sum,
+// over each of its attributes, of N / count, where N is the number of assets in
+// the collection and count is the number of assets having the same value for that
+// trait type. Assets with rarer trait values therefore score higher.
+func (a *ARC69) RarityScores(ctx context.Context, assetIDs []uint64, opts ...Option) (map[uint64]float64, error) {
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[Attribute]int)
+	for _, meta := range metas {
+		for _, attr := range meta.Attributes {
+			counts[Attribute{TraitType: attr.TraitType, Value: attr.ValueString()}]++
+		}
+	}
+
+	scores := make(map[uint64]float64, len(metas))
+	total := float64(len(metas))
+	for id, meta := range metas {
+		var score float64
+		for _, attr := range meta.Attributes {
+			score += total / float64(counts[Attribute{TraitType: attr.TraitType, Value: attr.ValueString()}])
+		}
+		scores[id] = score
+	}
+
+	return scores, nil
+}
+
+// CollectionMimeTypes fetches the metadata of the given assets and returns the
+// number of assets having each mime type, as returned by InferMimeType. Assets
+// whose mime type cannot be inferred are counted under the empty string.
+func (a *ARC69) CollectionMimeTypes(ctx context.Context, assetIDs []uint64, opts ...Option) (map[string]int, error) {
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, meta := range metas {
+		counts[meta.InferMimeType()]++
+	}
+
+	return counts, nil
+}
+
+// SameTraits fetches the metadata of two assets and reports whether they have the
+// same set of traits. Attributes are first normalized with every NormalizeFlag, so
+// trait types are compared case-insensitively while values remain case-sensitive,
+// and surrounding whitespace and the formatting of numbers are ignored. Traits are
+// compared as sets of trait type and value pairs, so a duplicated attribute counts
+// once, and a trait type with several values must have the same values on both.
+func (a *ARC69) SameTraits(ctx context.Context, assetA, assetB uint64, opts ...Option) (bool, error) {
+	metaA, err := a.Fetch(ctx, assetA, opts...)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch asset %d: %s", assetA, err)
+	}
+	metaB, err := a.Fetch(ctx, assetB, opts...)
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch asset %d: %s", assetB, err)
+	}
+
+	return reflect.DeepEqual(traitSet(metaA), traitSet(metaB)), nil
+}
+
+// Helper function that returns the set of normalized attributes of some metadata.
+func traitSet(meta *Metadata) map[Attribute]bool {
+	normalized := &Metadata{Attributes: append([]Attribute(nil), meta.Attributes...)}
+	normalized.Normalize(NormalizeTraitTypes | TrimValues | CoerceNumbers)
+
+	set := make(map[Attribute]bool, len(normalized.Attributes))
+	for _, attr := range normalized.Attributes {
+		set[Attribute{TraitType: attr.TraitType, Value: attr.ValueString()}] = true
+	}
+	return set
+}
+
+// FindDuplicates fetches the metadata of the given assets and groups the assets
+// holding identical metadata. Groups are keyed by the hex encoded SHA-256 hash of
+// the metadata's JSON encoding, which is canonical since properties are encoded
+// with sorted keys. Only groups with more than one asset are returned, each in
+// the order of assetIDs. An asset repeated in assetIDs is only counted once, so it
+// is never reported as a duplicate of itself.
+func (a *ARC69) FindDuplicates(ctx context.Context, assetIDs []uint64, opts ...Option) (map[string][]uint64, error) {
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]uint64)
+	seen := make(map[uint64]bool, len(assetIDs))
+	for _, id := range assetIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		data, err := metas[id].JSON()
+		if err != nil {
+			return nil, fmt.Errorf("unable to hash metadata of asset %d: %s", id, err)
+		}
+		hash := sha256.Sum256(data)
+		key := hex.EncodeToString(hash[:])
+		groups[key] = append(groups[key], id)
+	}
+
+	for key, ids := range groups {
+		if len(ids) < 2 {
+			delete(groups, key)
+		}
+	}
+
+	return groups, nil
+}
+
+// FindMissingFields fetches the metadata of the given assets and reports, for each
+// asset missing any of the given fields, which of them are empty or absent, in the
+// order of fields. Fields are named as in ARC69 JSON: "standard", "description",
+// "external_url", "media_url", "properties", "mime_type" and "attributes". Assets
+// having all of the fields are omitted from the report.
+func (a *ARC69) FindMissingFields(ctx context.Context, assetIDs []uint64, fields []string, opts ...Option) (map[uint64][]string, error) {
+	for _, field := range fields {
+		if _, ok := fieldPresence[field]; !ok {
+			return nil, fmt.Errorf("unknown metadata field: %s", field)
+		}
+	}
+
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make(map[uint64][]string)
+	for id, meta := range metas {
+		for _, field := range fields {
+			if !fieldPresence[field](meta) {
+				report[id] = append(report[id], field)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// fieldPresence reports whether each ARC69 field is set on some metadata.
+var fieldPresence = map[string]func(*Metadata) bool{
+	"standard":     func(m *Metadata) bool { return m.Standard != "" },
+	"description":  (*Metadata).HasDescription,
+	"external_url": (*Metadata).HasExternalURL,
+	"media_url":    (*Metadata).HasMediaURL,
+	"properties":   func(m *Metadata) bool { return len(m.Properties) > 0 },
+	"mime_type":    (*Metadata).HasMimeType,
+	"attributes":   func(m *Metadata) bool { return len(m.Attributes) > 0 },
+}
+
+// ExportAttributes fetches the metadata of the given assets and writes a table of
+// their trait values to w for use by rarity tools. format is either "csv", which
+// writes an asset_id column followed by a column per trait type, or "json", which
+// writes an array with one object per asset holding its asset_id and an object of
+// its traits keyed by trait type. Rows follow the order of assetIDs, and a trait
+// an asset lacks is left empty. In CSV, a trait type that is asset_id, or that
+// starts with "trait:", is written in the header with a "trait:" prefix (ex.
+// "trait:asset_id") so that every column name stays unique.
+func (a *ARC69) ExportAttributes(ctx context.Context, assetIDs []uint64, w io.Writer, format string, opts ...Option) error {
+	if format != "csv" && format != "json" {
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	metas, err := a.BatchFetch(ctx, assetIDs, opts...)
+	if err != nil {
+		return err
+	}
+
+	traitSet := make(map[string]bool)
+	for _, meta := range metas {
+		for _, attr := range meta.Attributes {
+			traitSet[attr.TraitType] = true
+		}
+	}
+	traits := make([]string, 0, len(traitSet))
+	for trait := range traitSet {
+		traits = append(traits, trait)
+	}
+	sort.Strings(traits)
+
+	// Helper function that returns the value of each trait of an asset.
+	values := func(meta *Metadata) map[string]string {
+		vals := make(map[string]string, len(traits))
+		for _, attr := range meta.Attributes {
+			if _, ok := vals[attr.TraitType]; !ok {
+				vals[attr.TraitType] = attr.ValueString()
+			}
+		}
+		return vals
+	}
+
+	if format == "json" {
+		type row struct {
+			AssetID uint64            `json:"asset_id"`
+			Traits  map[string]string `json:"traits"`
+		}
+		rows := make([]row, 0, len(assetIDs))
+		for _, id := range assetIDs {
+			vals := values(metas[id])
+			r := row{AssetID: id, Traits: make(map[string]string, len(traits))}
+			for _, trait := range traits {
+				r.Traits[trait] = vals[trait]
+			}
+			rows = append(rows, r)
+		}
+		return json.NewEncoder(w).Encode(rows)
+	}
+
+	header := []string{"asset_id"}
+	for _, trait := range traits {
+		if trait == "asset_id" || strings.HasPrefix(trait, "trait:") {
+			trait = "trait:" + trait
+		}
+		header = append(header, trait)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, id := range assetIDs {
+		vals := values(metas[id])
+		record := []string{strconv.FormatUint(id, 10)}
+		for _, trait := range traits {
+			record = append(record, vals[trait])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
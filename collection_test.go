@@ -0,0 +1,254 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestBatchFetch(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "one"})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Description: "two"})
+	a := New(nil, idx.client(t))
+
+	got, err := a.BatchFetch(context.Background(), []uint64{1, 2})
+	if err != nil {
+		t.Fatalf("BatchFetch() failed with error: %s, want success", err)
+	}
+	if len(got) != 2 || got[1].Description != "one" || got[2].Description != "two" {
+		t.Errorf("BatchFetch() = %+v, want metadata for assets 1 and 2", got)
+	}
+
+	if _, err := a.BatchFetch(context.Background(), []uint64{1, 3}); err == nil {
+		t.Errorf("BatchFetch() with missing asset succeeded, want error")
+	}
+}
+
+func TestCollectionTraits(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+	}})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Crown"},
+	}})
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Red"},
+	}})
+
+	got, err := New(nil, idx.client(t)).CollectionTraits(context.Background(), []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("CollectionTraits() failed with error: %s, want success", err)
+	}
+
+	want := map[string]map[string]int{
+		"Background": {"Blue": 2, "Red": 1},
+		"Hat":        {"Cap": 1, "Crown": 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectionTraits() = %v, want %v", got, want)
+	}
+}
+
+func TestExportAttributes(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+	}})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Red"},
+	}})
+	a := New(nil, idx.client(t))
+
+	tests := []struct {
+		format, want string
+	}{
+		{"csv", "asset_id,Background,Hat\n1,Blue,Cap\n2,Red,\n"},
+		{"json", `[{"asset_id":1,"traits":{"Background":"Blue","Hat":"Cap"}},{"asset_id":2,"traits":{"Background":"Red","Hat":""}}]` + "\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if err := a.ExportAttributes(context.Background(), []uint64{1, 2}, &buf, test.format); err != nil {
+			t.Errorf("ExportAttributes(%q) failed with error: %s, want success", test.format, err)
+			continue
+		}
+		if buf.String() != test.want {
+			t.Errorf("ExportAttributes(%q) wrote %q, want %q", test.format, buf.String(), test.want)
+		}
+	}
+
+	if err := a.ExportAttributes(context.Background(), []uint64{1}, &bytes.Buffer{}, "xml"); err == nil {
+		t.Errorf("ExportAttributes(\"xml\") succeeded, want error")
+	}
+
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "asset_id", Value: "forged"},
+	}})
+	idx.addMetadata(t, 4, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "trait:asset_id", Value: "escaped"},
+	}})
+	var buf bytes.Buffer
+	if err := a.ExportAttributes(context.Background(), []uint64{3, 4}, &buf, "csv"); err != nil {
+		t.Fatalf("ExportAttributes(\"csv\") with an asset_id trait failed with error: %s, want success", err)
+	}
+	if want := "asset_id,trait:asset_id,trait:trait:asset_id\n3,forged,\n4,,escaped\n"; buf.String() != want {
+		t.Errorf("ExportAttributes(\"csv\") with an asset_id trait wrote %q, want %q", buf.String(), want)
+	}
+	buf.Reset()
+	if err := a.ExportAttributes(context.Background(), []uint64{3}, &buf, "json"); err != nil {
+		t.Fatalf("ExportAttributes(\"json\") with an asset_id trait failed with error: %s, want success", err)
+	}
+	if want := `[{"asset_id":3,"traits":{"asset_id":"forged"}}]` + "\n"; buf.String() != want {
+		t.Errorf("ExportAttributes(\"json\") with an asset_id trait wrote %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRarityScores(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Cap"},
+	}})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Hat", Value: "Crown"},
+	}})
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Red"},
+	}})
+
+	got, err := New(nil, idx.client(t)).RarityScores(context.Background(), []uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("RarityScores() failed with error: %s, want success", err)
+	}
+
+	// Blue appears on 2 of 3 assets, Red, Cap and Crown on 1 of 3.
+	want := map[uint64]float64{
+		1: 3.0/2 + 3.0/1,
+		2: 3.0/2 + 3.0/1,
+		3: 3.0 / 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RarityScores() = %v, want %v", got, want)
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dup := &Metadata{Standard: "arc69", Description: "copy", Properties: map[string]interface{}{"a": "aa", "b": "bb"}}
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, dup)
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Description: "unique"})
+	idx.addMetadata(t, 3, 10, dup)
+
+	a := New(nil, idx.client(t))
+
+	got, err := a.FindDuplicates(context.Background(), []uint64{1, 2, 3, 1})
+	if err != nil {
+		t.Fatalf("FindDuplicates() failed with error: %s, want success", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("FindDuplicates() = %v, want a single group", got)
+	}
+	for _, ids := range got {
+		if want := []uint64{1, 3}; !reflect.DeepEqual(ids, want) {
+			t.Errorf("FindDuplicates() group = %v, want %v", ids, want)
+		}
+	}
+
+	if got, err := a.FindDuplicates(context.Background(), []uint64{1, 1, 2}); err != nil || len(got) != 0 {
+		t.Errorf("FindDuplicates() with a repeated asset = %v, %v, want no groups", got, err)
+	}
+}
+
+func TestFindMissingFields(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "complete", MediaURL: "ipfs://1"})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", MediaURL: "ipfs://2"})
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69"})
+	a := New(nil, idx.client(t))
+
+	got, err := a.FindMissingFields(context.Background(), []uint64{1, 2, 3}, []string{"media_url", "description"})
+	if err != nil {
+		t.Fatalf("FindMissingFields() failed with error: %s, want success", err)
+	}
+	want := map[uint64][]string{
+		2: {"description"},
+		3: {"media_url", "description"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FindMissingFields() = %v, want %v", got, want)
+	}
+
+	if _, err := a.FindMissingFields(context.Background(), []uint64{1}, []string{"name"}); err == nil {
+		t.Errorf("FindMissingFields() with unknown field succeeded, want error")
+	}
+}
+
+func TestSameTraits(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "Blue"},
+		{TraitType: "Level", Value: "3"},
+	}})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "level", Value: "3.0"},
+		{TraitType: " background", Value: "Blue "},
+		{TraitType: "Background", Value: "Blue"},
+	}})
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Background", Value: "blue"},
+		{TraitType: "Level", Value: "3"},
+	}})
+	idx.addMetadata(t, 5, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Serial", Value: "123456789012345678901234"},
+	}})
+	idx.addMetadata(t, 6, 10, &Metadata{Standard: "arc69", Attributes: []Attribute{
+		{TraitType: "Serial", Value: "123456789012345678901235"},
+	}})
+	a := New(nil, idx.client(t))
+
+	tests := []struct {
+		assetA, assetB uint64
+		want           bool
+	}{
+		{1, 2, true},
+		{1, 3, false},
+		{5, 6, false},
+	}
+	for _, test := range tests {
+		got, err := a.SameTraits(context.Background(), test.assetA, test.assetB)
+		if err != nil {
+			t.Errorf("SameTraits(%d, %d) failed with error: %s, want success", test.assetA, test.assetB, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("SameTraits(%d, %d) = %t, want %t", test.assetA, test.assetB, got, test.want)
+		}
+	}
+
+	if _, err := a.SameTraits(context.Background(), 1, 4); err == nil {
+		t.Errorf("SameTraits() with missing asset succeeded, want error")
+	}
+}
+
+func TestCollectionMimeTypes(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", MimeType: "image/png"})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", MediaURL: "ipfs://cid/2.png"})
+	idx.addMetadata(t, 3, 10, &Metadata{Standard: "arc69", MediaURL: "ipfs://cid/3.mp4"})
+	idx.addMetadata(t, 4, 10, &Metadata{Standard: "arc69", MediaURL: "ipfs://cid"})
+
+	got, err := New(nil, idx.client(t)).CollectionMimeTypes(context.Background(), []uint64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatalf("CollectionMimeTypes() failed with error: %s, want success", err)
+	}
+	want := map[string]int{"image/png": 2, "video/mp4": 1, "": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CollectionMimeTypes() = %v, want %v", got, want)
+	}
+}
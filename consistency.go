@@ -0,0 +1,48 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// CheckConsistency fetches the params and ARC69 metadata of an asset and returns
+// warnings about likely misconfigurations: a "name" property that differs from the
+// asset name, or a MIME type that disagrees with the extension of the media URL.
+// No warnings means no inconsistency was found.
+func (a *ARC69) CheckConsistency(ctx context.Context, assetID uint64, opts ...Option) ([]string, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, ErrMissingClient
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch asset: %s", err)
+	}
+
+	meta, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	if name, ok := meta.Properties["name"].(string); ok && asset.Params.Name != "" && !strings.EqualFold(name, asset.Params.Name) {
+		warnings = append(warnings, fmt.Sprintf("properties.name: %q differs from asset name %q", name, asset.Params.Name))
+	}
+
+	if meta.MimeType != "" && meta.MediaURL != "" {
+		ext, mimeType := mimeTypeFromURL(meta.MediaURL)
+		if mimeType != "" && !strings.EqualFold(mimeType, meta.MimeType) {
+			warnings = append(warnings, fmt.Sprintf("mime_type: %q disagrees with media URL extension %q (%s)", meta.MimeType, ext, mimeType))
+		}
+	}
+
+	return warnings, nil
+}
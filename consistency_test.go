@@ -0,0 +1,48 @@
+package arc69
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addAsset(1, models.AssetParams{Name: "Creature #1", UnitName: "CR1"})
+	idx.addMetadata(t, 1, 10, &Metadata{
+		Standard:   "arc69",
+		MediaURL:   "ipfs://cid/creature.png",
+		MimeType:   "image/png",
+		Properties: map[string]interface{}{"name": "creature #1"},
+	})
+	idx.addAsset(2, models.AssetParams{Name: "Creature #2", UnitName: "CR2"})
+	idx.addMetadata(t, 2, 10, &Metadata{
+		Standard:   "arc69",
+		MediaURL:   "ipfs://cid/creature.mp4",
+		MimeType:   "image/png",
+		Properties: map[string]interface{}{"name": "Creature #3"},
+	})
+	a := New(nil, idx.client(t))
+
+	got, err := a.CheckConsistency(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("CheckConsistency() failed with error: %s, want success", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("CheckConsistency() of consistent asset = %q, want no warnings", got)
+	}
+
+	got, err = a.CheckConsistency(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("CheckConsistency() failed with error: %s, want success", err)
+	}
+	want := []string{
+		`properties.name: "Creature #3" differs from asset name "Creature #2"`,
+		`mime_type: "image/png" disagrees with media URL extension ".mp4" (video/mp4)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckConsistency() = %q, want %q", got, want)
+	}
+}
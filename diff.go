@@ -0,0 +1,265 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Diff returns a field-level description of the differences between a and b.
+// Each entry has the form "<field>: <a value> -> <b value>", where nested
+// properties are reported using their "." delimited path (ex. "properties.p1.p2")
+// and attributes by index (ex. "attributes[0]"). A value missing on one side is
+// reported as <missing>. Numbers are compared by value, so a json.Number from a
+// fetched note equals the float64 or int holding the same number locally. An
+// empty result means the metadata are equal.
+func Diff(a, b *Metadata) []string {
+	if a == nil {
+		a = &Metadata{}
+	}
+	if b == nil {
+		b = &Metadata{}
+	}
+
+	var diffs []string
+	diffField := func(name, x, y string) {
+		if x != y {
+			diffs = append(diffs, fmt.Sprintf("%s: %q -> %q", name, x, y))
+		}
+	}
+
+	diffField("standard", a.Standard, b.Standard)
+	diffField("description", a.Description, b.Description)
+	diffField("external_url", a.ExternalURL, b.ExternalURL)
+	diffField("media_url", a.MediaURL, b.MediaURL)
+	diffField("mime_type", a.MimeType, b.MimeType)
+	diffs = append(diffs, diffProperties("properties", normalizeNumbers(a.Properties).(map[string]interface{}), normalizeNumbers(b.Properties).(map[string]interface{}))...)
+
+	for i := 0; i < len(a.Attributes) || i < len(b.Attributes); i++ {
+		name := fmt.Sprintf("attributes[%d]", i)
+		switch {
+		case i >= len(a.Attributes):
+			diffs = append(diffs, fmt.Sprintf("%s: <missing> -> %s", name, formatAttribute(b.Attributes[i])))
+		case i >= len(b.Attributes):
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> <missing>", name, formatAttribute(a.Attributes[i])))
+		case !sameAttribute(a.Attributes[i], b.Attributes[i]):
+			diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", name, formatAttribute(a.Attributes[i]), formatAttribute(b.Attributes[i])))
+		}
+	}
+
+	return diffs
+}
+
+// CompareOnChain fetches the current ARC69 metadata for an asset and compares it
+// against local. It returns whether they match along with the Diff from the
+// on-chain metadata to local.
+func (a *ARC69) CompareOnChain(ctx context.Context, assetID uint64, local *Metadata, opts ...Option) (bool, []string, error) {
+	onChain, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return false, nil, err
+	}
+
+	diffs := Diff(onChain, local)
+	return len(diffs) == 0, diffs, nil
+}
+
+// DiffVersions returns the Diff from an older to a newer version of the ARC69
+// metadata of an asset, both given by their index in FetchHistory, where 0 is the
+// newest version. An error is returned if the indices are equal or out of range.
+func (a *ARC69) DiffVersions(ctx context.Context, assetID uint64, newerIndex, olderIndex int, opts ...Option) ([]string, error) {
+	if newerIndex == olderIndex {
+		return nil, fmt.Errorf("cannot diff version %d with itself", newerIndex)
+	}
+
+	versions, err := a.FetchHistory(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range []int{newerIndex, olderIndex} {
+		if index < 0 || index >= len(versions) {
+			return nil, fmt.Errorf("version %d out of range for asset %d with %d versions", index, assetID, len(versions))
+		}
+	}
+
+	return Diff(versions[olderIndex].Metadata, versions[newerIndex].Metadata), nil
+}
+
+// patchOp is an operation of an RFC 6902 JSON patch.
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// HistoryPatches returns the RFC 6902 JSON patches that transformed the ARC69
+// metadata of an asset over its history, from the oldest to the newest version.
+// The patch at index i turns the JSON encoding of version i into that of version
+// i+1, using add, remove and replace operations, so it holds one patch less than
+// there are versions. Objects are patched key by key and arrays index by index.
+func (a *ARC69) HistoryPatches(ctx context.Context, assetID uint64, opts ...Option) ([]json.RawMessage, error) {
+	versions, err := a.FetchHistory(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]interface{}, len(versions))
+	for i, version := range versions {
+		// FetchHistory returns the newest version first.
+		data, err := version.Metadata.JSON()
+		if err != nil {
+			return nil, err
+		}
+		if err := newNumberDecoder(bytes.NewReader(data)).Decode(&docs[len(versions)-1-i]); err != nil {
+			return nil, fmt.Errorf("unable to decode metadata: %s", err)
+		}
+	}
+
+	patches := make([]json.RawMessage, 0, len(docs)-1)
+	for i := 1; i < len(docs); i++ {
+		ops := []patchOp{}
+		if err := diffJSON("", docs[i-1], docs[i], &ops); err != nil {
+			return nil, err
+		}
+		patch, err := json.Marshal(ops)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode patch: %s", err)
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches, nil
+}
+
+// Helper function that appends to ops the JSON patch operations turning the
+// decoded JSON value a at path into b.
+func diffJSON(path string, a, b interface{}, ops *[]patchOp) error {
+	// Helper function that appends an operation carrying a value.
+	add := func(op, path string, v interface{}) error {
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("unable to encode patch value: %s", err)
+		}
+		*ops = append(*ops, patchOp{Op: op, Path: path, Value: value})
+		return nil
+	}
+
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			return add("replace", path, b)
+		}
+
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			child := path + "/" + escapePointer(k)
+			x, inA := a[k]
+			y, inB := b[k]
+			var err error
+			switch {
+			case !inA:
+				err = add("add", child, y)
+			case !inB:
+				*ops = append(*ops, patchOp{Op: "remove", Path: child})
+			default:
+				err = diffJSON(child, x, y, ops)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			return add("replace", path, b)
+		}
+
+		for i := 0; i < len(a) && i < len(b); i++ {
+			if err := diffJSON(fmt.Sprintf("%s/%d", path, i), a[i], b[i], ops); err != nil {
+				return err
+			}
+		}
+		for i := len(a); i < len(b); i++ {
+			if err := add("add", fmt.Sprintf("%s/%d", path, i), b[i]); err != nil {
+				return err
+			}
+		}
+		// Remove trailing elements from the end so that indices stay valid.
+		for i := len(a) - 1; i >= len(b); i-- {
+			*ops = append(*ops, patchOp{Op: "remove", Path: fmt.Sprintf("%s/%d", path, i)})
+		}
+		return nil
+	default:
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return add("replace", path, b)
+	}
+}
+
+// Helper function that escapes a key for use in a JSON pointer.
+func escapePointer(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+// Helper function to format an attribute as its JSON encoding.
+func formatAttribute(attr Attribute) string {
+	data, _ := json.Marshal(attr)
+	return string(data)
+}
+
+// Helper function to recursively diff two properties maps.
+func diffProperties(prefix string, a, b map[string]interface{}) []string {
+	keys := make(map[string]bool)
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, k := range sorted {
+		path := prefix + "." + k
+		x, inA := a[k]
+		y, inB := b[k]
+		switch {
+		case !inA:
+			diffs = append(diffs, fmt.Sprintf("%s: <missing> -> %v", path, y))
+		case !inB:
+			diffs = append(diffs, fmt.Sprintf("%s: %v -> <missing>", path, x))
+		default:
+			xm, xIsMap := x.(map[string]interface{})
+			ym, yIsMap := y.(map[string]interface{})
+			if xIsMap && yIsMap {
+				diffs = append(diffs, diffProperties(path, xm, ym)...)
+			} else if !reflect.DeepEqual(x, y) {
+				diffs = append(diffs, fmt.Sprintf("%s: %v -> %v", path, x, y))
+			}
+		}
+	}
+
+	return diffs
+}
@@ -0,0 +1,164 @@
+package arc69
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Metadata{
+		Standard:    "arc69",
+		Description: "old",
+		Properties: map[string]interface{}{
+			"a": "aa",
+			"b": map[string]interface{}{"bb": "bbb"},
+		},
+		Attributes: []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+	b := &Metadata{
+		Standard:    "arc69",
+		Description: "new",
+		Properties: map[string]interface{}{
+			"b": map[string]interface{}{"bb": "ccc"},
+			"c": "cc",
+		},
+	}
+
+	got := Diff(a, b)
+	want := []string{
+		`description: "old" -> "new"`,
+		"properties.a: aa -> <missing>",
+		"properties.b.bb: bbb -> ccc",
+		"properties.c: <missing> -> cc",
+		`attributes[0]: {"trait_type":"Background","value":"Blue"} -> <missing>`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %q, want %q", got, want)
+	}
+
+	if got := Diff(a, a); len(got) != 0 {
+		t.Errorf("Diff(a, a) = %q, want no differences", got)
+	}
+}
+
+func TestCompareOnChain(t *testing.T) {
+	onChain := &Metadata{Standard: "arc69", Description: "deployed", MediaURL: "ipfs://media"}
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, onChain)
+	a := New(nil, idx.client(t))
+
+	match, diffs, err := a.CompareOnChain(context.Background(), 1, &Metadata{Standard: "arc69", Description: "deployed", MediaURL: "ipfs://media"})
+	if err != nil {
+		t.Fatalf("CompareOnChain() failed with error: %s, want success", err)
+	}
+	if !match || len(diffs) != 0 {
+		t.Errorf("CompareOnChain() = %t, %q, want true with no differences", match, diffs)
+	}
+
+	match, diffs, err = a.CompareOnChain(context.Background(), 1, &Metadata{Standard: "arc69", Description: "drifted", MediaURL: "ipfs://media"})
+	if err != nil {
+		t.Fatalf("CompareOnChain() failed with error: %s, want success", err)
+	}
+	want := []string{`description: "deployed" -> "drifted"`}
+	if match || !reflect.DeepEqual(diffs, want) {
+		t.Errorf("CompareOnChain() = %t, %q, want false, %q", match, diffs, want)
+	}
+}
+
+func TestCompareOnChainNumbers(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 10, []byte(`{"standard":"arc69","properties":{"level":3,"ratio":0.5,"supply":9007199254740993}}`))
+	a := New(nil, idx.client(t))
+
+	var local Metadata
+	if err := json.Unmarshal([]byte(`{"standard":"arc69","properties":{"level":3.0,"ratio":0.5,"supply":9007199254740993}}`), &local); err != nil {
+		t.Fatalf("json.Unmarshal() failed with error: %s", err)
+	}
+	local.Properties["supply"] = uint64(9007199254740993)
+
+	match, diffs, err := a.CompareOnChain(context.Background(), 1, &local)
+	if err != nil {
+		t.Fatalf("CompareOnChain() failed with error: %s, want success", err)
+	}
+	if !match || len(diffs) != 0 {
+		t.Errorf("CompareOnChain() of numbers decoded as float64 = %t, %q, want true with no differences", match, diffs)
+	}
+
+	local.Properties["level"] = 4
+	_, diffs, err = a.CompareOnChain(context.Background(), 1, &local)
+	if want := []string{"properties.level: 3 -> 4"}; err != nil || !reflect.DeepEqual(diffs, want) {
+		t.Errorf("CompareOnChain() = %q, %v, want %q", diffs, err, want)
+	}
+}
+
+func TestDiffVersions(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1", Attributes: []Attribute{{TraitType: "Hat", Value: "Cap"}}})
+	idx.addMetadata(t, 1, 11, &Metadata{Standard: "arc69", Description: "v2", Attributes: []Attribute{{TraitType: "Hat", Value: "Crown"}}})
+	a := New(nil, idx.client(t))
+
+	got, err := a.DiffVersions(context.Background(), 1, 0, 1)
+	if err != nil {
+		t.Fatalf("DiffVersions() failed with error: %s, want success", err)
+	}
+	want := []string{
+		`description: "v1" -> "v2"`,
+		`attributes[0]: {"trait_type":"Hat","value":"Cap"} -> {"trait_type":"Hat","value":"Crown"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffVersions() = %q, want %q", got, want)
+	}
+
+	for _, indices := range [][2]int{{0, 0}, {0, 2}, {-1, 0}} {
+		if _, err := a.DiffVersions(context.Background(), 1, indices[0], indices[1]); err == nil {
+			t.Errorf("DiffVersions(%d, %d) succeeded, want error", indices[0], indices[1])
+		}
+	}
+}
+
+func TestHistoryPatches(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{
+		Standard:    "arc69",
+		Description: "v1",
+		Properties:  map[string]interface{}{"a/b": "x", "gone": "y"},
+		Attributes:  []Attribute{{TraitType: "Hat", Value: "Cap"}, {TraitType: "Eyes", Value: "Red"}},
+	})
+	idx.addMetadata(t, 1, 11, &Metadata{
+		Standard:    "arc69",
+		Description: "v2",
+		Properties:  map[string]interface{}{"a/b": "z", "new": 1},
+		Attributes:  []Attribute{{TraitType: "Hat", Value: "Crown"}},
+	})
+	idx.addMetadata(t, 1, 12, &Metadata{
+		Standard:    "arc69",
+		Description: "v3",
+		Properties:  map[string]interface{}{"a/b": "z", "new": 1},
+		Attributes:  []Attribute{{TraitType: "Hat", Value: "Crown"}},
+	})
+	a := New(nil, idx.client(t))
+
+	got, err := a.HistoryPatches(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("HistoryPatches() failed with error: %s, want success", err)
+	}
+	want := []string{
+		`[{"op":"replace","path":"/attributes/0/value","value":"Crown"},` +
+			`{"op":"remove","path":"/attributes/1"},` +
+			`{"op":"replace","path":"/description","value":"v2"},` +
+			`{"op":"replace","path":"/properties/a~1b","value":"z"},` +
+			`{"op":"remove","path":"/properties/gone"},` +
+			`{"op":"add","path":"/properties/new","value":1}]`,
+		`[{"op":"replace","path":"/description","value":"v3"}]`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("HistoryPatches() returned %d patches, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if string(got[i]) != want[i] {
+			t.Errorf("HistoryPatches()[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
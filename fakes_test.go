@@ -0,0 +1,397 @@
+package arc69
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// fakeIndexer is an in-memory stand-in for the indexer REST API. It serves the
+// endpoints used by this package from the assets and transactions it is loaded
+// with and records every request it receives.
+type fakeIndexer struct {
+	mu     sync.Mutex
+	assets map[uint64]models.Asset
+	txns   map[uint64][]models.Transaction
+	// balances holds the holdings of each asset, returned unfiltered.
+	balances map[uint64][]models.MiniAssetHolding
+	// pages, when set for an asset, overrides how its transactions are paginated.
+	pages map[uint64][][]models.Transaction
+	// searchPageSize, when set, is the number of results per page of transaction
+	// and asset searches and balance lookups.
+	searchPageSize int
+	// round is the round reported by health checks, which fail when unhealthy is set.
+	round     uint64
+	unhealthy bool
+	// noAssetSearch makes asset searches fail as on indexers without the endpoint.
+	noAssetSearch bool
+	requests      []*url.URL
+	server        *httptest.Server
+}
+
+func newFakeIndexer(t *testing.T) *fakeIndexer {
+	f := &fakeIndexer{
+		assets:   make(map[uint64]models.Asset),
+		txns:     make(map[uint64][]models.Transaction),
+		balances: make(map[uint64][]models.MiniAssetHolding),
+		pages:    make(map[uint64][][]models.Transaction),
+	}
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns an indexer client pointed at the fake.
+func (f *fakeIndexer) client(t *testing.T) *indexer.Client {
+	c, err := indexer.MakeClient(f.server.URL, "")
+	if err != nil {
+		t.Fatalf("indexer.MakeClient() failed with error: %s", err)
+	}
+	return c
+}
+
+// addAsset makes the asset with the given params available to lookups.
+func (f *fakeIndexer) addAsset(assetID uint64, params models.AssetParams) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.assets[assetID] = models.Asset{Index: assetID, Params: params}
+}
+
+// addNote records an acfg transaction carrying note for the asset at the given round.
+func (f *fakeIndexer) addNote(assetID, round uint64, note []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txns[assetID] = append(f.txns[assetID], models.Transaction{
+		Id:             "tx-" + strconv.FormatUint(assetID, 10) + "-" + strconv.FormatUint(round, 10),
+		Type:           "acfg",
+		ConfirmedRound: round,
+		RoundTime:      round,
+		Note:           note,
+		AssetConfigTransaction: models.TransactionAssetConfig{
+			AssetId: assetID,
+		},
+	})
+}
+
+// addTxn records an arbitrary transaction for the asset.
+func (f *fakeIndexer) addTxn(assetID uint64, txn models.Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txns[assetID] = append(f.txns[assetID], txn)
+}
+
+// addMetadata records an acfg transaction whose note is the JSON encoding of meta.
+func (f *fakeIndexer) addMetadata(t *testing.T, assetID, round uint64, meta *Metadata) {
+	note, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("json.Marshal(%+v) failed with error: %s", meta, err)
+	}
+	f.addNote(assetID, round, note)
+}
+
+// requestsTo returns the recorded requests whose path matches path.
+func (f *fakeIndexer) requestsTo(path string) []*url.URL {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var reqs []*url.URL
+	for _, u := range f.requests {
+		if u.Path == path {
+			reqs = append(reqs, u)
+		}
+	}
+	return reqs
+}
+
+func (f *fakeIndexer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL)
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.URL.Path == "/health":
+		if f.unhealthy {
+			http.Error(w, `{"message":"database unavailable"}`, http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, models.HealthCheckResponse{DbAvailable: true, Round: f.round})
+	case len(parts) == 4 && parts[0] == "v2" && parts[1] == "assets" && parts[3] == "transactions":
+		id, _ := strconv.ParseUint(parts[2], 10, 64)
+		pages, ok := f.pages[id]
+		if !ok {
+			minRound, _ := strconv.ParseUint(r.URL.Query().Get("min-round"), 10, 64)
+			maxRound, err := strconv.ParseUint(r.URL.Query().Get("max-round"), 10, 64)
+			if err != nil {
+				maxRound = math.MaxUint64
+			}
+			resp := models.TransactionsResponse{Transactions: []models.Transaction{}}
+			for _, txn := range f.txns[id] {
+				if txn.ConfirmedRound >= minRound && txn.ConfirmedRound <= maxRound {
+					resp.Transactions = append(resp.Transactions, txn)
+				}
+			}
+			writeJSON(w, resp)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("next"))
+		resp := models.TransactionsResponse{}
+		if page < len(pages) {
+			resp.Transactions = pages[page]
+			resp.NextToken = strconv.Itoa(page + 1)
+		}
+		writeJSON(w, resp)
+	case len(parts) == 4 && parts[0] == "v2" && parts[1] == "assets" && parts[3] == "balances":
+		id, _ := strconv.ParseUint(parts[2], 10, 64)
+		balances := f.balances[id]
+		resp := models.AssetBalancesResponse{Balances: balances}
+		if f.searchPageSize > 0 {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("next"))
+			resp.Balances = nil
+			if offset < len(balances) {
+				end := offset + f.searchPageSize
+				if end > len(balances) {
+					end = len(balances)
+				}
+				resp.Balances = balances[offset:end]
+				resp.NextToken = strconv.Itoa(end)
+			}
+		}
+		writeJSON(w, resp)
+	case len(parts) == 2 && parts[0] == "v2" && parts[1] == "assets":
+		if f.noAssetSearch {
+			http.Error(w, `{"message":"unsupported endpoint"}`, http.StatusNotFound)
+			return
+		}
+		var matches []models.Asset
+		for _, asset := range f.assets {
+			query := r.URL.Query()
+			if creator := query.Get("creator"); creator != "" && asset.Params.Creator != creator {
+				continue
+			}
+			if unit := query.Get("unit"); unit != "" && !strings.EqualFold(asset.Params.UnitName, unit) {
+				continue
+			}
+			matches = append(matches, asset)
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].Index < matches[j].Index })
+		resp := models.AssetsResponse{Assets: matches}
+		if f.searchPageSize > 0 {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("next"))
+			resp.Assets = nil
+			if offset < len(matches) {
+				end := offset + f.searchPageSize
+				if end > len(matches) {
+					end = len(matches)
+				}
+				resp.Assets = matches[offset:end]
+				resp.NextToken = strconv.Itoa(end)
+			}
+		}
+		writeJSON(w, resp)
+	case len(parts) == 3 && parts[0] == "v2" && parts[1] == "assets":
+		id, _ := strconv.ParseUint(parts[2], 10, 64)
+		asset, ok := f.assets[id]
+		if !ok {
+			http.Error(w, `{"message":"no assets found"}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, models.AssetResponse{Asset: asset})
+	case len(parts) == 2 && parts[0] == "v2" && parts[1] == "transactions":
+		query := r.URL.Query()
+		minRound, _ := strconv.ParseUint(query.Get("min-round"), 10, 64)
+		maxRound, err := strconv.ParseUint(query.Get("max-round"), 10, 64)
+		if err != nil {
+			maxRound = math.MaxUint64
+		}
+		var matches []models.Transaction
+		for _, txns := range f.txns {
+			for _, txn := range txns {
+				if txn.ConfirmedRound < minRound || txn.ConfirmedRound > maxRound {
+					continue
+				}
+				if txType := query.Get("tx-type"); txType != "" && txn.Type != txType {
+					continue
+				}
+				matches = append(matches, txn)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].ConfirmedRound != matches[j].ConfirmedRound {
+				return matches[i].ConfirmedRound < matches[j].ConfirmedRound
+			}
+			return matches[i].Id < matches[j].Id
+		})
+		resp := models.TransactionsResponse{Transactions: matches}
+		if f.searchPageSize > 0 {
+			offset, _ := strconv.Atoi(query.Get("next"))
+			resp.Transactions = nil
+			if offset < len(matches) {
+				end := offset + f.searchPageSize
+				if end > len(matches) {
+					end = len(matches)
+				}
+				resp.Transactions = matches[offset:end]
+				resp.NextToken = strconv.Itoa(end)
+			}
+		}
+		writeJSON(w, resp)
+	case len(parts) == 3 && parts[0] == "v2" && parts[1] == "transactions":
+		for _, txns := range f.txns {
+			for _, txn := range txns {
+				if txn.Id == parts[2] {
+					writeJSON(w, models.TransactionResponse{Transaction: txn})
+					return
+				}
+			}
+		}
+		http.Error(w, `{"message":"no transaction found"}`, http.StatusNotFound)
+	default:
+		http.Error(w, `{"message":"unsupported endpoint"}`, http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// fakeAlgod is an in-memory stand-in for the algod REST API. Submitted
+// transactions are confirmed immediately and, when an indexer is attached,
+// recorded on it so that they can be read back.
+type fakeAlgod struct {
+	mu      sync.Mutex
+	params  models.TransactionParametersResponse
+	round   uint64
+	sent    []types.SignedTxn
+	rawSent [][]byte
+	// unconfirmed makes pending transaction lookups never report a confirmation.
+	unconfirmed bool
+	// stallPending makes pending transaction lookups hang until the request is
+	// canceled. It must be set before the fake is used.
+	stallPending bool
+	indexer      *fakeIndexer
+	server       *httptest.Server
+}
+
+func newFakeAlgod(t *testing.T, idx *fakeIndexer) *fakeAlgod {
+	f := &fakeAlgod{
+		params: models.TransactionParametersResponse{
+			ConsensusVersion: "future",
+			Fee:              0,
+			GenesisHash:      make([]byte, 32),
+			GenesisId:        "testnet-v1.0",
+			LastRound:        100,
+			MinFee:           1000,
+		},
+		round:   100,
+		indexer: idx,
+	}
+	f.server = httptest.NewServer(f)
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client returns an algod client pointed at the fake.
+func (f *fakeAlgod) client(t *testing.T) *algod.Client {
+	c, err := algod.MakeClient(f.server.URL, "")
+	if err != nil {
+		t.Fatalf("algod.MakeClient() failed with error: %s", err)
+	}
+	return c
+}
+
+// sentTxns returns the signed transactions submitted so far.
+func (f *fakeAlgod) sentTxns() []types.SignedTxn {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]types.SignedTxn(nil), f.sent...)
+}
+
+func (f *fakeAlgod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.stallPending && strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/") {
+		<-r.Context().Done()
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/v2/transactions/params":
+		writeJSON(w, f.params)
+	case r.URL.Path == "/v2/transactions" && r.Method == http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		f.rawSent = append(f.rawSent, body)
+		dec := msgpack.NewDecoder(bytes.NewReader(body))
+		var txID string
+		for {
+			var stxn types.SignedTxn
+			if err := dec.Decode(&stxn); err == io.EOF {
+				break
+			} else if err != nil {
+				http.Error(w, `{"message":"invalid transaction"}`, http.StatusBadRequest)
+				return
+			}
+			f.round++
+			f.sent = append(f.sent, stxn)
+			txID = crypto.TransactionIDString(stxn.Txn)
+			if f.indexer != nil && stxn.Txn.Type == types.AssetConfigTx {
+				assetID := uint64(stxn.Txn.ConfigAsset)
+				f.indexer.addTxn(assetID, models.Transaction{
+					Id:             txID,
+					Type:           "acfg",
+					ConfirmedRound: f.round,
+					RoundTime:      f.round,
+					Sender:         stxn.Txn.Sender.String(),
+					Note:           stxn.Txn.Note,
+					AssetConfigTransaction: models.TransactionAssetConfig{
+						AssetId: assetID,
+					},
+				})
+			}
+		}
+		writeJSON(w, models.PostTransactionsResponse{Txid: txID})
+	case r.URL.Path == "/v2/status" || strings.HasPrefix(r.URL.Path, "/v2/status/wait-for-block-after/"):
+		writeJSON(w, models.NodeStatus{LastRound: f.round})
+	case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+		resp := models.PendingTransactionInfoResponse{ConfirmedRound: f.round}
+		if f.unconfirmed {
+			resp.ConfirmedRound = 0
+			f.round++
+		}
+		w.Write(msgpack.Encode(resp))
+	default:
+		http.Error(w, `{"message":"unsupported endpoint"}`, http.StatusNotFound)
+	}
+}
+
+// newTestAsset registers an asset managed by a new account on idx and returns
+// the account.
+func newTestAsset(idx *fakeIndexer, assetID uint64) crypto.Account {
+	account := crypto.GenerateAccount()
+	addr := account.Address.String()
+	idx.addAsset(assetID, models.AssetParams{
+		Creator:  addr,
+		Manager:  addr,
+		Reserve:  addr,
+		Freeze:   addr,
+		Clawback: addr,
+	})
+	return account
+}
@@ -6,4 +6,5 @@ require (
 	github.com/algorand/go-algorand-sdk v1.11.0
 	github.com/google/go-querystring v1.1.0 // indirect
 	golang.org/x/crypto v0.0.0-20211215153901-e495a2d5b3d3 // indirect
+	golang.org/x/time v0.3.0
 )
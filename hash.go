@@ -0,0 +1,95 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// VerifyNoteHash fetches the raw note holding the latest metadata of an asset and
+// reports whether its SHA-256 hash equals expected. The computed hash is returned
+// as well so callers can log it.
+func (a *ARC69) VerifyNoteHash(ctx context.Context, assetID uint64, expected [32]byte, opts ...Option) (bool, [32]byte, error) {
+	note, err := a.FetchRaw(ctx, assetID, opts...)
+	if err != nil {
+		return false, [32]byte{}, err
+	}
+
+	hash := sha256.Sum256(note)
+	return hash == expected, hash, nil
+}
+
+// FetchAndVerifyHash fetches the ARC69 metadata of an asset like Fetch and reports
+// whether the SHA-256 hash of the note holding it equals the MetadataHash committed
+// in the asset's params. An error is returned if the asset has no metadata hash.
+func (a *ARC69) FetchAndVerifyHash(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, bool, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, false, ErrMissingClient
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to fetch asset %d: %s", assetID, err)
+	}
+	if len(asset.Params.MetadataHash) == 0 {
+		return nil, false, fmt.Errorf("asset %d has no metadata hash", assetID)
+	}
+
+	note, meta, err := a.metadataNote(ctx, cfg, assetID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hash := sha256.Sum256(note)
+	return meta, bytes.Equal(hash[:], asset.Params.MetadataHash), nil
+}
+
+// Hash returns the SHA-256 hash of the JSON encoding of m, which is canonical since
+// properties are encoded with sorted keys. It is meant to be committed as the
+// metadata hash of an asset when creating it with MakeAssetCreateTxn, and matches
+// the note written by Update when neither compression nor a note prefix is used,
+// so it can later be checked with FetchAndVerifyHash.
+func (m *Metadata) Hash() ([32]byte, error) {
+	data, err := m.JSON()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
+
+// Fingerprint fetches the latest ARC69 metadata of an asset and returns its
+// fingerprint, as returned by Metadata.Fingerprint, so that change feeds can
+// detect updates by comparing a single string.
+func (a *ARC69) Fingerprint(ctx context.Context, assetID uint64, opts ...Option) (string, error) {
+	meta, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := meta.Hash()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// Fingerprint returns the hex encoded SHA-256 hash of the JSON encoding of m.
+// Editing any field changes it, while the order in which properties were set does
+// not since they are encoded with sorted keys. An empty string is returned if m
+// cannot be encoded.
+func (m *Metadata) Fingerprint() string {
+	hash, err := m.Hash()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,165 @@
+package arc69
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+func TestVerifyNoteHash(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 10, []byte(`{"standard":"arc69"}`))
+	a := New(nil, idx.client(t))
+
+	var expected [32]byte
+	hex.Decode(expected[:], []byte("49ca6b8abeff8174bfd55e6f27d906855b3c655b372f482581d57f04263cc851"))
+
+	match, hash, err := a.VerifyNoteHash(context.Background(), 1, expected)
+	if err != nil {
+		t.Fatalf("VerifyNoteHash() failed with error: %s, want success", err)
+	}
+	if !match || hash != expected {
+		t.Errorf("VerifyNoteHash() = %t, %x, want true, %x", match, hash, expected)
+	}
+
+	match, hash, err = a.VerifyNoteHash(context.Background(), 1, [32]byte{})
+	if err != nil {
+		t.Fatalf("VerifyNoteHash() failed with error: %s, want success", err)
+	}
+	if match || hash != expected {
+		t.Errorf("VerifyNoteHash() = %t, %x, want false, %x", match, hash, expected)
+	}
+}
+
+func TestFetchAndVerifyHash(t *testing.T) {
+	note := []byte(`{"standard":"arc69","description":"committed"}`)
+	hash := sha256.Sum256(note)
+
+	idx := newFakeIndexer(t)
+	idx.addAsset(1, models.AssetParams{MetadataHash: hash[:]})
+	idx.addNote(1, 10, note)
+	idx.addAsset(2, models.AssetParams{MetadataHash: make([]byte, 32)})
+	idx.addNote(2, 10, note)
+	idx.addAsset(3, models.AssetParams{})
+	idx.addNote(3, 10, note)
+	idx.addAsset(4, models.AssetParams{MetadataHash: hash[:]})
+	idx.addNote(4, 10, note)
+	idx.addNote(4, 20, []byte("not metadata"))
+	a := New(nil, idx.client(t))
+
+	meta, match, err := a.FetchAndVerifyHash(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchAndVerifyHash() failed with error: %s, want success", err)
+	}
+	if !match || meta.Description != "committed" {
+		t.Errorf("FetchAndVerifyHash() = %+v, %t, want committed metadata, true", meta, match)
+	}
+
+	if _, match, err := a.FetchAndVerifyHash(context.Background(), 2); err != nil || match {
+		t.Errorf("FetchAndVerifyHash() of tampered asset = %t, %v, want false, nil", match, err)
+	}
+
+	if _, _, err := a.FetchAndVerifyHash(context.Background(), 3); err == nil {
+		t.Errorf("FetchAndVerifyHash() of asset without hash succeeded, want error")
+	}
+
+	meta, match, err = a.FetchAndVerifyHash(context.Background(), 4)
+	if err != nil {
+		t.Fatalf("FetchAndVerifyHash() of asset with a newer non-ARC69 note failed with error: %s, want success", err)
+	}
+	if !match || meta.Description != "committed" {
+		t.Errorf("FetchAndVerifyHash() of asset with a newer non-ARC69 note = %+v, %t, want committed metadata, true", meta, match)
+	}
+	if _, _, err := a.FetchAndVerifyHash(context.Background(), 4, WithLatestOnly()); err == nil {
+		t.Errorf("FetchAndVerifyHash(WithLatestOnly()) of asset with a non-ARC69 latest note succeeded, want error")
+	}
+}
+
+func TestMetadataHash(t *testing.T) {
+	a := &Metadata{Standard: "arc69", Properties: map[string]interface{}{}}
+	a.Properties["a"] = "aa"
+	a.Properties["b"] = map[string]interface{}{"c": "cc", "d": "dd"}
+	b := &Metadata{Standard: "arc69", Properties: map[string]interface{}{
+		"b": map[string]interface{}{"d": "dd", "c": "cc"},
+		"a": "aa",
+	}}
+
+	hashA, err := a.Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed with error: %s, want success", err)
+	}
+	hashB, err := b.Hash()
+	if err != nil {
+		t.Fatalf("Hash() failed with error: %s, want success", err)
+	}
+	if hashA != hashB {
+		t.Errorf("Hash() = %x and %x for equivalent metadata, want equal hashes", hashA, hashB)
+	}
+
+	data, _ := a.JSON()
+	if want := sha256.Sum256(data); hashA != want {
+		t.Errorf("Hash() = %x, want %x", hashA, want)
+	}
+}
+
+func TestMetadataFingerprint(t *testing.T) {
+	base := func() *Metadata {
+		return &Metadata{
+			Standard:    "arc69",
+			Description: "original",
+			ExternalURL: "https://example.com",
+			MediaURL:    "ipfs://cid",
+			Properties:  map[string]interface{}{"a": "aa", "b": map[string]interface{}{"c": "cc", "d": "dd"}},
+			MimeType:    "image/png",
+			Attributes:  []Attribute{{TraitType: "Background", Value: "Blue"}},
+		}
+	}
+	want := base().Fingerprint()
+	if len(want) != 64 {
+		t.Fatalf("Fingerprint() = %q, want hex encoded SHA-256 hash", want)
+	}
+
+	reordered := base()
+	reordered.Properties = map[string]interface{}{"b": map[string]interface{}{"d": "dd", "c": "cc"}, "a": "aa"}
+	if got := reordered.Fingerprint(); got != want {
+		t.Errorf("Fingerprint() of reordered properties = %s, want %s", got, want)
+	}
+
+	edits := map[string]func(m *Metadata){
+		"description":  func(m *Metadata) { m.Description = "edited" },
+		"external_url": func(m *Metadata) { m.ExternalURL = "https://example.org" },
+		"media_url":    func(m *Metadata) { m.MediaURL = "ipfs://other" },
+		"properties":   func(m *Metadata) { m.Properties["a"] = "ab" },
+		"mime_type":    func(m *Metadata) { m.MimeType = "image/gif" },
+		"attributes":   func(m *Metadata) { m.Attributes[0].Value = "Red" },
+	}
+	for field, edit := range edits {
+		meta := base()
+		edit(meta)
+		if got := meta.Fingerprint(); got == want {
+			t.Errorf("Fingerprint() after editing %s = %s, want a different fingerprint", field, got)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Description: "fetched"}
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, meta)
+	a := New(nil, idx.client(t))
+
+	got, err := a.Fingerprint(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fingerprint() failed with error: %s, want success", err)
+	}
+	if want := meta.Fingerprint(); got != want {
+		t.Errorf("Fingerprint() = %s, want %s", got, want)
+	}
+
+	if _, err := a.Fingerprint(context.Background(), 2); err == nil {
+		t.Errorf("Fingerprint() of asset without metadata succeeded, want error")
+	}
+}
@@ -0,0 +1,50 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// maxIndexerLag is the number of rounds the indexer can trail algod by before
+// HealthCheck reports it as out of sync.
+const maxIndexerLag = 10
+
+// HealthCheck checks that the configured algod and indexer are reachable and that
+// the indexer is no more than 10 rounds behind algod. The returned error names
+// each client that failed.
+func (a *ARC69) HealthCheck(ctx context.Context, opts ...Option) error {
+	cfg := a.config(opts)
+	if cfg.algodClient == nil || cfg.indexerClient == nil {
+		return ErrMissingClient
+	}
+
+	var failures []string
+	var status models.NodeStatus
+	algodErr := observe(ctx, cfg, "algod.Status", func(ctx context.Context) (err error) {
+		status, err = cfg.algodClient.Status().Do(ctx)
+		return err
+	})
+	if algodErr != nil {
+		failures = append(failures, fmt.Sprintf("algod: %s", algodErr))
+	}
+	var health models.HealthCheckResponse
+	indexerErr := observe(ctx, cfg, "indexer.HealthCheck", func(ctx context.Context) (err error) {
+		health, err = cfg.indexerClient.HealthCheck().Do(ctx)
+		return err
+	})
+	if indexerErr != nil {
+		failures = append(failures, fmt.Sprintf("indexer: %s", indexerErr))
+	}
+
+	if algodErr == nil && indexerErr == nil && status.LastRound > health.Round+maxIndexerLag {
+		failures = append(failures, fmt.Sprintf("indexer: %d rounds behind algod", status.LastRound-health.Round))
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("health check failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
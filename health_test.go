@@ -0,0 +1,33 @@
+package arc69
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestHealthCheck(t *testing.T) {
+	idx := newFakeIndexer(t)
+	algod := newFakeAlgod(t, idx)
+	idx.round = algod.round
+	a := New(algod.client(t), idx.client(t))
+
+	if err := a.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck() failed with error: %s, want success", err)
+	}
+
+	idx.mu.Lock()
+	idx.round = algod.round - 50
+	idx.mu.Unlock()
+	if err := a.HealthCheck(context.Background()); err == nil || !strings.Contains(err.Error(), "50 rounds behind") {
+		t.Errorf("HealthCheck() with lagging indexer = %v, want error reporting the lag", err)
+	}
+
+	idx.mu.Lock()
+	idx.unhealthy = true
+	idx.mu.Unlock()
+	err := a.HealthCheck(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "indexer:") || strings.Contains(err.Error(), "algod:") {
+		t.Errorf("HealthCheck() with failing indexer = %v, want error naming the indexer only", err)
+	}
+}
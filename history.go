@@ -0,0 +1,289 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// MetadataVersion is a version of an asset's ARC69 metadata along with the acfg
+// transaction that set it.
+type MetadataVersion struct {
+	Metadata *Metadata
+	TxID     string
+	Round    uint64
+	// RoundTime is the time the transaction was confirmed, in seconds since the epoch.
+	RoundTime uint64
+	// Sender is the address that sent the transaction.
+	Sender string
+	// Transaction is the transaction as returned by the indexer. It is only set
+	// when WithRawTransactions is used.
+	Transaction *models.Transaction
+}
+
+// NoteRecord is the note of an acfg transaction, whether or not it holds ARC69 metadata.
+type NoteRecord struct {
+	Note  []byte
+	TxID  string
+	Round uint64
+	// ParsedAsARC69 holds the metadata in Note, or nil if it is not valid ARC69 metadata.
+	ParsedAsARC69 *Metadata
+}
+
+// FetchAllNotes retrieves the note of every acfg transaction of an asset that has
+// one, in the order returned by the indexer, which is from the oldest to the newest.
+func (a *ARC69) FetchAllNotes(ctx context.Context, assetID uint64, opts ...Option) ([]NoteRecord, error) {
+	cfg := a.config(opts)
+	var records []NoteRecord
+	err := a.iterateAcfg(ctx, cfg, assetID, func(txn models.Transaction) error {
+		if len(txn.Note) == 0 {
+			return nil
+		}
+
+		record := NoteRecord{Note: txn.Note, TxID: txn.Id, Round: txn.ConfirmedRound}
+		if meta, err := parseNote(txn.Note, cfg); err == nil && meta.IsValid() {
+			record.ParsedAsARC69 = meta
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// FetchHistory retrieves every version of the ARC69 metadata of an asset, ordered
+// from the newest to the oldest. Notes that do not hold valid ARC69 metadata are
+// skipped. An error is returned if the asset has no ARC69 metadata.
+func (a *ARC69) FetchHistory(ctx context.Context, assetID uint64, opts ...Option) ([]MetadataVersion, error) {
+	var versions []MetadataVersion
+	err := a.IterateHistory(ctx, assetID, func(v MetadataVersion) error {
+		versions = append(versions, v)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no ARC69 metadata found for asset %d", assetID)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Round > versions[j].Round
+	})
+	return versions, nil
+}
+
+// FetchOriginal retrieves the oldest version of the ARC69 metadata of an asset,
+// which is usually the one set when the asset was created. If the asset was
+// created without metadata, the first valid metadata added later is returned.
+func (a *ARC69) FetchOriginal(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, error) {
+	versions, err := a.FetchHistory(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions[len(versions)-1].Metadata, nil
+}
+
+// FetchVersion retrieves a version of the ARC69 metadata of an asset by its index
+// in FetchHistory, where 0 is the newest version, 1 the one before it and so on.
+// An error is returned if index is out of range.
+func (a *ARC69) FetchVersion(ctx context.Context, assetID uint64, index int, opts ...Option) (*Metadata, error) {
+	versions, err := a.FetchHistory(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= len(versions) {
+		return nil, fmt.Errorf("version %d out of range for asset %d with %d versions", index, assetID, len(versions))
+	}
+
+	return versions[index].Metadata, nil
+}
+
+// LastUpdated returns the time at which the latest valid ARC69 metadata of an
+// asset was confirmed. An error is returned if the asset has no ARC69 metadata.
+func (a *ARC69) LastUpdated(ctx context.Context, assetID uint64, opts ...Option) (time.Time, error) {
+	versions, err := a.FetchHistory(ctx, assetID, opts...)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(int64(versions[0].RoundTime), 0), nil
+}
+
+// IterateHistory calls fn with every version of the ARC69 metadata of an asset in
+// the order returned by the indexer, which is from the oldest to the newest, paging
+// through the indexer as needed. Notes that do not hold valid ARC69 metadata are
+// skipped. Iteration stops at the first error returned by fn, which is returned.
+func (a *ARC69) IterateHistory(ctx context.Context, assetID uint64, fn func(MetadataVersion) error, opts ...Option) error {
+	cfg := a.config(opts)
+	return a.iterateAcfg(ctx, cfg, assetID, func(txn models.Transaction) error {
+		if len(txn.Note) == 0 {
+			return nil
+		}
+
+		meta, err := parseNote(txn.Note, cfg)
+		if err != nil || !meta.IsValid() {
+			return nil
+		}
+
+		version := MetadataVersion{
+			Metadata:  meta,
+			TxID:      txn.Id,
+			Round:     txn.ConfirmedRound,
+			RoundTime: txn.RoundTime,
+			Sender:    txn.Sender,
+		}
+		if cfg.rawTransactions {
+			raw := txn
+			version.Transaction = &raw
+		}
+		return fn(version)
+	})
+}
+
+// UpdateCount returns the number of acfg transactions of an asset, paging through
+// the indexer as needed. Notes are only parsed when validOnly is true, in which
+// case only the transactions holding valid ARC69 metadata are counted.
+func (a *ARC69) UpdateCount(ctx context.Context, assetID uint64, validOnly bool, opts ...Option) (int, error) {
+	cfg := a.config(opts)
+	count := 0
+	err := a.iterateAcfg(ctx, cfg, assetID, func(txn models.Transaction) error {
+		if validOnly {
+			if len(txn.Note) == 0 {
+				return nil
+			}
+			if meta, err := parseNote(txn.Note, cfg); err != nil || !meta.IsValid() {
+				return nil
+			}
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// UpdaterAddresses returns the distinct addresses that have set the ARC69 metadata
+// of an asset, in the order of their first update.
+func (a *ARC69) UpdaterAddresses(ctx context.Context, assetID uint64, opts ...Option) ([]string, error) {
+	var addrs []string
+	seen := make(map[string]bool)
+	err := a.IterateHistory(ctx, assetID, func(version MetadataVersion) error {
+		if !seen[version.Sender] {
+			seen[version.Sender] = true
+			addrs = append(addrs, version.Sender)
+		}
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return addrs, nil
+}
+
+// ChangedAssets returns, in ascending order, the IDs of the assets whose ARC69
+// metadata was updated by an acfg transaction confirmed between minRound and
+// maxRound inclusive, paging through the indexer as needed.
+func (a *ARC69) ChangedAssets(ctx context.Context, minRound, maxRound uint64, opts ...Option) ([]uint64, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, ErrMissingClient
+	}
+
+	changed := make(map[uint64]bool)
+	next := ""
+	for {
+		var resp models.TransactionsResponse
+		err := observe(ctx, cfg, "indexer.SearchForTransactions", func(ctx context.Context) (err error) {
+			resp, err = cfg.indexerClient.SearchForTransactions().TxType("acfg").MinRound(minRound).MaxRound(maxRound).NextToken(next).Do(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txn := range resp.Transactions {
+			if len(txn.Note) == 0 {
+				continue
+			}
+			if meta, err := parseNote(txn.Note, cfg); err != nil || !meta.IsValid() {
+				continue
+			}
+
+			assetID := txn.AssetConfigTransaction.AssetId
+			if assetID == 0 {
+				assetID = txn.CreatedAssetIndex
+			}
+			changed[assetID] = true
+		}
+
+		if resp.NextToken == "" || len(resp.Transactions) == 0 {
+			break
+		}
+		next = resp.NextToken
+	}
+
+	ids := make([]uint64, 0, len(changed))
+	for id := range changed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids, nil
+}
+
+// iterateAcfg calls fn with every acfg transaction of an asset, from the oldest to
+// the newest, paging through the indexer. Transactions repeated across pages are
+// only passed to fn once.
+func (a *ARC69) iterateAcfg(ctx context.Context, cfg *options, assetID uint64, fn func(models.Transaction) error) error {
+	if cfg.indexerClient == nil {
+		return ErrMissingClient
+	}
+
+	seen := make(map[string]bool)
+	next := ""
+	for {
+		query := cfg.indexerClient.LookupAssetTransactions(assetID).TxType("acfg").NextToken(next)
+		if cfg.indexerRound > 0 {
+			query = query.MaxRound(cfg.indexerRound)
+		}
+
+		var resp models.TransactionsResponse
+		err := observe(ctx, cfg, "indexer.LookupAssetTransactions", func(ctx context.Context) (err error) {
+			resp, err = query.Do(ctx)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, txn := range resp.Transactions {
+			if seen[txn.Id] {
+				continue
+			}
+			seen[txn.Id] = true
+
+			if err := fn(txn); err != nil {
+				return err
+			}
+		}
+
+		if resp.NextToken == "" || len(resp.Transactions) == 0 {
+			return nil
+		}
+		next = resp.NextToken
+	}
+}
@@ -0,0 +1,296 @@
+package arc69
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+func TestFetchHistory(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(1, 11, []byte("not metadata"))
+	idx.addNote(1, 12, nil)
+	idx.addMetadata(t, 1, 13, &Metadata{Standard: "arc69", Description: "v2"})
+
+	got, err := New(nil, idx.client(t)).FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory() failed with error: %s, want success", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("FetchHistory() returned %d versions, want 2", len(got))
+	}
+	if got[0].Metadata.Description != "v2" || got[0].Round != 13 || got[0].TxID != "tx-1-13" {
+		t.Errorf("FetchHistory()[0] = %+v, want v2 at round 13", got[0])
+	}
+	if got[1].Metadata.Description != "v1" || got[1].Round != 10 {
+		t.Errorf("FetchHistory()[1] = %+v, want v1 at round 10", got[1])
+	}
+
+	if _, err := New(nil, idx.client(t)).FetchHistory(context.Background(), 2); err == nil {
+		t.Errorf("FetchHistory() for asset without metadata succeeded, want error")
+	}
+}
+
+func TestFetchHistoryDeduplicatesAcrossPages(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addMetadata(t, 1, 11, &Metadata{Standard: "arc69", Description: "v2"})
+	idx.addMetadata(t, 1, 12, &Metadata{Standard: "arc69", Description: "v3"})
+	txns := idx.txns[1]
+	idx.pages[1] = [][]models.Transaction{txns[0:2], txns[1:3]}
+
+	got, err := New(nil, idx.client(t)).FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory() failed with error: %s, want success", err)
+	}
+
+	var descriptions []string
+	for _, v := range got {
+		descriptions = append(descriptions, v.Metadata.Description)
+	}
+	if len(descriptions) != 3 || descriptions[0] != "v3" || descriptions[1] != "v2" || descriptions[2] != "v1" {
+		t.Errorf("FetchHistory() = %q, want [v3 v2 v1]", descriptions)
+	}
+
+	if got := len(idx.requestsTo("/v2/assets/1/transactions")); got != 3 {
+		t.Errorf("FetchHistory() made %d indexer requests, want 3", got)
+	}
+}
+
+func TestFetchAllNotes(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(1, 11, []byte("hello world"))
+	idx.addNote(1, 12, []byte(`{"standard":"arc3"}`))
+	idx.addNote(1, 13, nil)
+
+	got, err := New(nil, idx.client(t)).FetchAllNotes(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchAllNotes() failed with error: %s, want success", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("FetchAllNotes() returned %d records, want 3", len(got))
+	}
+
+	if got[0].ParsedAsARC69 == nil || got[0].ParsedAsARC69.Description != "v1" || got[0].Round != 10 {
+		t.Errorf("FetchAllNotes()[0] = %+v, want ARC69 metadata at round 10", got[0])
+	}
+	if got[1].ParsedAsARC69 != nil || string(got[1].Note) != "hello world" || got[1].TxID != "tx-1-11" {
+		t.Errorf("FetchAllNotes()[1] = %+v, want unparsed plain-text note", got[1])
+	}
+	if got[2].ParsedAsARC69 != nil {
+		t.Errorf("FetchAllNotes()[2] = %+v, want non-ARC69 JSON left unparsed", got[2])
+	}
+}
+
+func TestFetchOriginal(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 5, nil)
+	idx.addNote(1, 6, []byte("not metadata"))
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addMetadata(t, 1, 11, &Metadata{Standard: "arc69", Description: "v2"})
+	idx.addMetadata(t, 1, 12, &Metadata{Standard: "arc69", Description: "v3"})
+
+	got, err := New(nil, idx.client(t)).FetchOriginal(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchOriginal() failed with error: %s, want success", err)
+	}
+	if got.Description != "v1" {
+		t.Errorf("FetchOriginal() = %q, want \"v1\"", got.Description)
+	}
+}
+
+func TestChangedAssets(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.searchPageSize = 2
+	idx.addMetadata(t, 1, 5, &Metadata{Standard: "arc69", Description: "too early"})
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addMetadata(t, 1, 12, &Metadata{Standard: "arc69", Description: "v2"})
+	idx.addMetadata(t, 2, 11, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(3, 11, []byte("not metadata"))
+	idx.addMetadata(t, 4, 30, &Metadata{Standard: "arc69", Description: "too late"})
+
+	got, err := New(nil, idx.client(t)).ChangedAssets(context.Background(), 10, 20)
+	if err != nil {
+		t.Fatalf("ChangedAssets() failed with error: %s, want success", err)
+	}
+	if want := []uint64{1, 2}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedAssets() = %v, want %v", got, want)
+	}
+}
+
+func TestUpdaterAddresses(t *testing.T) {
+	idx := newFakeIndexer(t)
+	for i, sender := range []string{"ALICE", "BOB", "ALICE"} {
+		round := uint64(10 + i)
+		idx.addTxn(1, models.Transaction{
+			Id:                     []string{"tx-a", "tx-b", "tx-c"}[i],
+			Type:                   "acfg",
+			Sender:                 sender,
+			ConfirmedRound:         round,
+			RoundTime:              round,
+			Note:                   []byte(`{"standard":"arc69"}`),
+			AssetConfigTransaction: models.TransactionAssetConfig{AssetId: 1},
+		})
+	}
+	a := New(nil, idx.client(t))
+
+	got, err := a.UpdaterAddresses(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("UpdaterAddresses() failed with error: %s, want success", err)
+	}
+	if want := []string{"ALICE", "BOB"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UpdaterAddresses() = %q, want %q", got, want)
+	}
+
+	history, err := a.FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory() failed with error: %s, want success", err)
+	}
+	if history[0].Sender != "ALICE" || history[1].Sender != "BOB" {
+		t.Errorf("FetchHistory() senders = %q, %q, want \"ALICE\", \"BOB\"", history[0].Sender, history[1].Sender)
+	}
+}
+
+func TestUpdateCount(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(1, 11, nil)
+	idx.addNote(1, 12, []byte("gm"))
+	idx.addMetadata(t, 1, 13, &Metadata{Standard: "arc69", Description: "v2"})
+	idx.addMetadata(t, 1, 14, &Metadata{Standard: "arc69", Description: "v3"})
+	txns := idx.txns[1]
+	idx.pages[1] = [][]models.Transaction{txns[0:3], txns[3:5]}
+	a := New(nil, idx.client(t))
+
+	for validOnly, want := range map[bool]int{false: 5, true: 3} {
+		got, err := a.UpdateCount(context.Background(), 1, validOnly)
+		if err != nil {
+			t.Fatalf("UpdateCount(%t) failed with error: %s, want success", validOnly, err)
+		}
+		if got != want {
+			t.Errorf("UpdateCount(%t) = %d, want %d", validOnly, got, want)
+		}
+	}
+}
+
+func TestFetchVersion(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addNote(1, 11, []byte("gm"))
+	idx.addMetadata(t, 1, 12, &Metadata{Standard: "arc69", Description: "v2"})
+	a := New(nil, idx.client(t))
+
+	latest, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	got, err := a.FetchVersion(context.Background(), 1, 0)
+	if err != nil {
+		t.Fatalf("FetchVersion(0) failed with error: %s, want success", err)
+	}
+	if !got.Equal(latest) {
+		t.Errorf("FetchVersion(0) = %+v, want %+v", got, latest)
+	}
+
+	got, err = a.FetchVersion(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("FetchVersion(1) failed with error: %s, want success", err)
+	}
+	if got.Description != "v1" {
+		t.Errorf("FetchVersion(1) = %q, want \"v1\"", got.Description)
+	}
+
+	if _, err := a.FetchVersion(context.Background(), 1, 2); err == nil {
+		t.Errorf("FetchVersion(2) succeeded, want out of range error")
+	}
+}
+
+func TestFetchHistoryRawTransactions(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addTxn(1, models.Transaction{
+		Id:                     "tx-1",
+		Type:                   "acfg",
+		Fee:                    2000,
+		Group:                  []byte("group-id"),
+		ConfirmedRound:         10,
+		Note:                   []byte(`{"standard":"arc69"}`),
+		AssetConfigTransaction: models.TransactionAssetConfig{AssetId: 1},
+	})
+	a := New(nil, idx.client(t))
+
+	got, err := a.FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory() failed with error: %s, want success", err)
+	}
+	if got[0].Transaction != nil {
+		t.Errorf("FetchHistory() = %+v, want no raw transaction by default", got[0].Transaction)
+	}
+
+	got, err = a.FetchHistory(context.Background(), 1, WithRawTransactions())
+	if err != nil {
+		t.Fatalf("FetchHistory(WithRawTransactions()) failed with error: %s, want success", err)
+	}
+	raw := got[0].Transaction
+	if raw == nil || raw.Id != "tx-1" || raw.Fee != 2000 || string(raw.Group) != "group-id" {
+		t.Errorf("FetchHistory(WithRawTransactions()) transaction = %+v, want tx-1 with fee and group", raw)
+	}
+}
+
+func TestLastUpdated(t *testing.T) {
+	idx := newFakeIndexer(t)
+	for _, txn := range []models.Transaction{
+		{Id: "tx-1", ConfirmedRound: 10, RoundTime: 1600000000, Note: []byte(`{"standard":"arc69"}`)},
+		{Id: "tx-2", ConfirmedRound: 11, RoundTime: 1650000000, Note: []byte(`{"standard":"arc69"}`)},
+		{Id: "tx-3", ConfirmedRound: 12, RoundTime: 1700000000, Note: []byte("gm")},
+	} {
+		txn.Type = "acfg"
+		idx.addTxn(1, txn)
+	}
+	a := New(nil, idx.client(t))
+
+	got, err := a.LastUpdated(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("LastUpdated() failed with error: %s, want success", err)
+	}
+	if want := time.Unix(1650000000, 0); !got.Equal(want) {
+		t.Errorf("LastUpdated() = %s, want %s", got, want)
+	}
+
+	if _, err := a.LastUpdated(context.Background(), 2); err == nil {
+		t.Errorf("LastUpdated() of asset without metadata succeeded, want error")
+	}
+}
+
+func TestWithIndexerRound(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	idx.addMetadata(t, 1, 20, &Metadata{Standard: "arc69", Description: "v2"})
+	a := New(nil, idx.client(t), WithIndexerRound(15))
+
+	got, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch(WithIndexerRound()) failed with error: %s, want success", err)
+	}
+	if got.Description != "v1" {
+		t.Errorf("Fetch(WithIndexerRound(15)) = %q, want \"v1\"", got.Description)
+	}
+
+	history, err := a.FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory(WithIndexerRound()) failed with error: %s, want success", err)
+	}
+	if len(history) != 1 {
+		t.Errorf("FetchHistory(WithIndexerRound(15)) returned %d versions, want 1", len(history))
+	}
+
+	for _, req := range idx.requestsTo("/v2/assets/1/transactions") {
+		if got := req.Query().Get("max-round"); got != "15" {
+			t.Errorf("indexer request %s has max-round %q, want \"15\"", req, got)
+		}
+	}
+}
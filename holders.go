@@ -0,0 +1,51 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+// FetchWithHolders fetches the ARC69 metadata of an asset like Fetch along with
+// the number of distinct accounts currently holding a nonzero balance of it,
+// paging through the indexer as needed. For popular assets the count can be
+// bounded with WithMaxHolders, in which case paging stops once that many holders
+// have been found and the count returned is that bound.
+func (a *ARC69) FetchWithHolders(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, uint64, error) {
+	meta, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cfg := a.config(opts)
+	holders := make(map[string]bool)
+	next := ""
+	for {
+		var resp models.AssetBalancesResponse
+		err := observe(ctx, cfg, "indexer.LookupAssetBalances", func(ctx context.Context) (err error) {
+			resp, err = cfg.indexerClient.LookupAssetBalances(assetID).CurrencyGreaterThan(0).NextToken(next).Do(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("unable to fetch holders of asset %d: %s", assetID, err)
+		}
+
+		for _, balance := range resp.Balances {
+			if balance.Amount == 0 || balance.Deleted {
+				continue
+			}
+			holders[balance.Address] = true
+			if cfg.maxHolders > 0 && uint64(len(holders)) >= cfg.maxHolders {
+				return meta, cfg.maxHolders, nil
+			}
+		}
+
+		if resp.NextToken == "" || len(resp.Balances) == 0 {
+			break
+		}
+		next = resp.NextToken
+	}
+
+	return meta, uint64(len(holders)), nil
+}
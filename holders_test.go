@@ -0,0 +1,39 @@
+package arc69
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+)
+
+func TestFetchWithHolders(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.searchPageSize = 2
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "held"})
+	idx.balances[1] = []models.MiniAssetHolding{
+		{Address: "ALICE", Amount: 1},
+		{Address: "BOB", Amount: 0},
+		{Address: "CAROL", Amount: 5},
+	}
+	a := New(nil, idx.client(t))
+
+	meta, holders, err := a.FetchWithHolders(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchWithHolders() failed with error: %s, want success", err)
+	}
+	if meta.Description != "held" {
+		t.Errorf("FetchWithHolders() metadata = %+v, want description \"held\"", meta)
+	}
+	if holders != 2 {
+		t.Errorf("FetchWithHolders() holders = %d, want 2", holders)
+	}
+	// Two pages of holders, then an empty page ending the pagination.
+	if got := len(idx.requestsTo("/v2/assets/1/balances")); got != 3 {
+		t.Errorf("FetchWithHolders() made %d balance requests, want 3", got)
+	}
+
+	if _, holders, err := a.FetchWithHolders(context.Background(), 1, WithMaxHolders(1)); err != nil || holders != 1 {
+		t.Errorf("FetchWithHolders(WithMaxHolders(1)) = %d, %v, want 1", holders, err)
+	}
+}
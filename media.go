@@ -0,0 +1,465 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// defaultIPFSGateway is used to resolve ipfs:// URLs when no gateway is configured.
+const defaultIPFSGateway = "https://ipfs.io/ipfs/"
+
+// defaultHTTPClient is used for URL fetches when no HTTP client is configured.
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// defaultMaxMediaSize is the largest media FetchMedia downloads when no limit is
+// configured.
+const defaultMaxMediaSize = 10 << 20
+
+// Sources of metadata reported by FetchWithFallback.
+const (
+	SourceNote = "note"
+	SourceURL  = "url"
+)
+
+// extensionMimeTypes maps common media file extensions to their MIME types.
+var extensionMimeTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".svg":  "image/svg+xml",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".glb":  "model/gltf-binary",
+	".gltf": "model/gltf+json",
+	".json": "application/json",
+	".html": "text/html",
+}
+
+// arc19Template matches the ARC19 template-ipfs URL placeholder.
+var arc19Template = regexp.MustCompile(`\{ipfscid:(0|1):(raw|dag-pb):reserve:sha2-256\}`)
+
+// FetchWithFallback attempts to retrieve the ARC69 metadata for an asset from its
+// latest acfg note and, if that fails, from the JSON document at the asset's URL
+// as used by ARC3 and ARC19 assets. A document that is not ARC69 metadata is
+// converted with FromARC3. Along with the metadata it returns the source that
+// produced it, either SourceNote or SourceURL.
+func (a *ARC69) FetchWithFallback(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, string, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, "", ErrMissingClient
+	}
+
+	meta, noteErr := a.Fetch(ctx, assetID, opts...)
+	if noteErr == nil {
+		return meta, SourceNote, nil
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to fetch asset: %s", err)
+	}
+
+	if asset.Params.Url == "" {
+		return nil, "", fmt.Errorf("no metadata found for asset %d: %s and asset has no URL", assetID, noteErr)
+	}
+
+	meta, err = fetchURLMetadata(ctx, cfg, asset.Params)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return meta, SourceURL, nil
+}
+
+// FetchMerged retrieves the effective metadata of a hybrid asset, whose base
+// metadata is the JSON at its URL and whose overrides are the ARC69 metadata in
+// its latest acfg note. The note takes precedence: its non-empty fields replace
+// those of the URL metadata, its properties are merged into the URL properties
+// recursively, with nested maps merged key by key and any other note value
+// replacing the URL one, and its attributes overwrite the URL attributes with the
+// same trait type as in MergeAttributes with Overwrite. An error is returned if
+// the asset has no URL or no ARC69 note.
+func (a *ARC69) FetchMerged(ctx context.Context, assetID uint64, opts ...Option) (*Metadata, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return nil, ErrMissingClient
+	}
+
+	var asset models.Asset
+	err := observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch asset: %s", err)
+	}
+	if asset.Params.Url == "" {
+		return nil, fmt.Errorf("asset %d has no URL", assetID)
+	}
+
+	base, err := fetchURLMetadata(ctx, cfg, asset.Params)
+	if err != nil {
+		return nil, err
+	}
+	note, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := base
+	for _, field := range []struct{ dst, src *string }{
+		{&merged.Standard, &note.Standard},
+		{&merged.Description, &note.Description},
+		{&merged.ExternalURL, &note.ExternalURL},
+		{&merged.MediaURL, &note.MediaURL},
+		{&merged.MimeType, &note.MimeType},
+	} {
+		if *field.src != "" {
+			*field.dst = *field.src
+		}
+	}
+	if len(note.Properties) > 0 {
+		if merged.Properties == nil {
+			merged.Properties = make(map[string]interface{})
+		}
+		mergeProperties(merged.Properties, note.clone().Properties)
+	}
+	merged.MergeAttributes(note.Attributes, Overwrite)
+
+	return merged, nil
+}
+
+// Helper function that merges src into dst recursively, with src taking precedence.
+func mergeProperties(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, srcOK := v.(map[string]interface{})
+		dstMap, dstOK := dst[k].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeProperties(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// fetchURLMetadata retrieves the metadata JSON at the URL of an asset. Documents
+// whose standard is not ARC69, such as ARC3 metadata, are converted with FromARC3
+// so that their image is kept as the media URL.
+func fetchURLMetadata(ctx context.Context, cfg *options, params models.AssetParams) (*Metadata, error) {
+	rawURL, err := resolveAssetURL(params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpRequest(ctx, cfg, http.MethodGet, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read metadata: %s", err)
+	}
+
+	var doc struct {
+		Standard string `json:"standard"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unable to parse metadata: %s", err)
+	}
+	if !strings.EqualFold(doc.Standard, "arc69") {
+		meta, _, err := FromARC3(data)
+		return meta, err
+	}
+
+	meta := &Metadata{}
+	if err := newNumberDecoder(bytes.NewReader(data)).Decode(meta); err != nil {
+		return nil, fmt.Errorf("unable to parse metadata: %s", err)
+	}
+
+	return meta, nil
+}
+
+// ValidateMediaAccessible issues an HTTP HEAD request to the media URL of m and
+// returns an error if the request fails or the response status is not 2xx.
+// ipfs:// URLs are resolved through the configured IPFS gateway.
+func (a *ARC69) ValidateMediaAccessible(ctx context.Context, m *Metadata, opts ...Option) error {
+	if m.MediaURL == "" {
+		return fmt.Errorf("no media URL provided")
+	}
+
+	resp, err := httpRequest(ctx, a.config(opts), http.MethodHead, m.MediaURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// VerifyMimeType issues an HTTP HEAD request to the media URL of m and reports
+// whether the Content-Type returned by the server matches the declared MimeType.
+// Parameters such as charset and letter case are ignored. ipfs:// URLs are
+// resolved through the configured IPFS gateway. An error is returned if m
+// declares no mime type or if the server does not report a Content-Type.
+func (a *ARC69) VerifyMimeType(ctx context.Context, m *Metadata, opts ...Option) (bool, error) {
+	if m.MediaURL == "" {
+		return false, fmt.Errorf("no media URL provided")
+	}
+	if m.MimeType == "" {
+		return false, fmt.Errorf("no mime type declared")
+	}
+
+	resp, err := httpRequest(ctx, a.config(opts), http.MethodHead, m.MediaURL)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		return false, fmt.Errorf("no content type reported for %s", m.MediaURL)
+	}
+	served, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false, fmt.Errorf("invalid content type %q: %s", contentType, err)
+	}
+	declared, _, err := mime.ParseMediaType(m.MimeType)
+	if err != nil {
+		return false, fmt.Errorf("invalid mime type %q: %s", m.MimeType, err)
+	}
+
+	return served == declared, nil
+}
+
+// FetchMedia downloads the media at the media URL of m and returns it along with
+// the content type reported by the server. ipfs:// URLs are resolved through the
+// configured IPFS gateway. An error is returned if the media is larger than the
+// limit set by WithMaxMediaSize, which defaults to 10 MiB.
+func (a *ARC69) FetchMedia(ctx context.Context, m *Metadata, opts ...Option) ([]byte, string, error) {
+	if m.MediaURL == "" {
+		return nil, "", fmt.Errorf("no media URL provided")
+	}
+
+	cfg := a.config(opts)
+	maxSize := cfg.maxMediaSize
+	if maxSize <= 0 {
+		maxSize = defaultMaxMediaSize
+	}
+
+	resp, err := httpRequest(ctx, cfg, http.MethodGet, m.MediaURL)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.ContentLength > maxSize {
+		return nil, "", fmt.Errorf("media at %s exceeds the maximum size of %d bytes", m.MediaURL, maxSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read media at %s: %s", m.MediaURL, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, "", fmt.Errorf("media at %s exceeds the maximum size of %d bytes", m.MediaURL, maxSize)
+	}
+
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// SetMimeFromContent sets m.MimeType to the media type sniffed from data using
+// http.DetectContentType. An existing MimeType is only overwritten when force is
+// true.
+func (m *Metadata) SetMimeFromContent(data []byte, force bool) {
+	if m.MimeType != "" && !force {
+		return
+	}
+
+	mimeType := http.DetectContentType(data)
+	m.MimeType = strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0])
+}
+
+// InferMimeType returns the declared MimeType of m or, when it is empty, the MIME
+// type implied by the file extension of its media URL. An empty string is
+// returned if neither is known.
+func (m *Metadata) InferMimeType() string {
+	if m.MimeType != "" {
+		return m.MimeType
+	}
+	_, mimeType := mimeTypeFromURL(m.MediaURL)
+	return mimeType
+}
+
+// Helper function that returns the extension of the path of rawURL and the MIME
+// type it implies, or an empty MIME type if the extension is unknown.
+func mimeTypeFromURL(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	return ext, extensionMimeTypes[ext]
+}
+
+// resolveURLs converts ipfs:// URLs into HTTP URLs served by each configured IPFS
+// gateway, in order. Any other URL is returned unchanged as the only candidate.
+func resolveURLs(rawURL string, cfg *options) []string {
+	if !strings.HasPrefix(rawURL, "ipfs://") {
+		return []string{rawURL}
+	}
+
+	gateways := cfg.ipfsGateways
+	if len(gateways) == 0 {
+		gateways = []string{defaultIPFSGateway}
+	}
+
+	path := strings.TrimPrefix(strings.TrimPrefix(rawURL, "ipfs://"), "ipfs/")
+	urls := make([]string, len(gateways))
+	for i, gateway := range gateways {
+		urls[i] = strings.TrimSuffix(gateway, "/") + "/" + path
+	}
+	return urls
+}
+
+// resolveAssetURL returns the location of the metadata referenced by the URL of
+// an asset. The ARC3 "#arc3" suffix is dropped and ARC19 template-ipfs URLs are
+// expanded into ipfs:// URLs using the CID derived from the reserve address.
+func resolveAssetURL(params models.AssetParams) (string, error) {
+	rawURL := strings.TrimSuffix(params.Url, "#arc3")
+	if !strings.HasPrefix(rawURL, "template-ipfs://") {
+		return rawURL, nil
+	}
+
+	match := arc19Template.FindStringSubmatch(rawURL)
+	if match == nil {
+		return "", fmt.Errorf("unsupported ARC19 URL template: %s", rawURL)
+	}
+
+	reserve, err := types.DecodeAddress(params.Reserve)
+	if err != nil {
+		return "", fmt.Errorf("invalid reserve address for ARC19 URL: %s", err)
+	}
+
+	// The reserve address holds the sha2-256 digest of the content.
+	multihash := append([]byte{0x12, 0x20}, reserve[:]...)
+
+	var cid string
+	switch {
+	case match[1] == "1" && match[2] == "raw":
+		cid = "b" + cidEncoding.EncodeToString(append([]byte{0x01, 0x55}, multihash...))
+	case match[1] == "1":
+		cid = "b" + cidEncoding.EncodeToString(append([]byte{0x01, 0x70}, multihash...))
+	case match[2] == "dag-pb":
+		cid = base58Encode(multihash)
+	default:
+		return "", fmt.Errorf("CIDv0 only supports dag-pb: %s", rawURL)
+	}
+
+	return "ipfs://" + strings.TrimPrefix(strings.Replace(rawURL, match[0], cid, 1), "template-ipfs://"), nil
+}
+
+// cidEncoding is the lowercase, unpadded base32 encoding used by CIDv1 strings.
+var cidEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// base58Alphabet is the bitcoin base58 alphabet used by CIDv0 strings.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// Helper function to base58 encode bytes.
+func base58Encode(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// httpRequest resolves rawURL and performs an HTTP request against it, returning
+// an error for network failures and non-2xx responses. ipfs:// URLs are requested
+// from each configured gateway in turn until one succeeds, and the failures of
+// every gateway are returned if none does. On success the caller is responsible
+// for closing the response body.
+func httpRequest(ctx context.Context, cfg *options, method, rawURL string) (*http.Response, error) {
+	urls := resolveURLs(rawURL, cfg)
+	if len(urls) == 1 {
+		return httpRequestURL(ctx, cfg, method, urls[0], rawURL)
+	}
+
+	var failures []string
+	for _, u := range urls {
+		resp, err := httpRequestURL(ctx, cfg, method, u, u)
+		if err == nil {
+			return resp, nil
+		}
+		failures = append(failures, err.Error())
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, fmt.Errorf("request to %s failed on every gateway: %s", rawURL, strings.Join(failures, "; "))
+}
+
+// Helper function that performs a single HTTP request against u, returning an
+// error naming name for network failures and non-2xx responses.
+func httpRequestURL(ctx context.Context, cfg *options, method, u, name string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request for %s: %s", name, err)
+	}
+
+	client := cfg.httpClient
+	if client == nil {
+		client = defaultHTTPClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %s", name, err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("request to %s failed with status: %s", name, resp.Status)
+	}
+
+	return resp, nil
+}
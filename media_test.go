@@ -0,0 +1,349 @@
+package arc69
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+)
+
+func TestValidateMediaAccessible(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("got %s request, want HEAD", r.Method)
+		}
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	a := New(nil, nil)
+	if err := a.ValidateMediaAccessible(context.Background(), &Metadata{MediaURL: srv.URL + "/media.png"}); err != nil {
+		t.Errorf("ValidateMediaAccessible() failed with error: %s, want success", err)
+	}
+
+	if err := a.ValidateMediaAccessible(context.Background(), &Metadata{MediaURL: srv.URL + "/missing.png"}); err == nil {
+		t.Errorf("ValidateMediaAccessible() with 404 media succeeded, want error")
+	}
+
+	meta := &Metadata{MediaURL: "ipfs://cid/media.png"}
+	if err := a.ValidateMediaAccessible(context.Background(), meta, WithIPFSGateway(srv.URL+"/ipfs/")); err != nil {
+		t.Errorf("ValidateMediaAccessible() with IPFS media failed with error: %s, want success", err)
+	}
+}
+
+func TestResolveURLs(t *testing.T) {
+	tests := []struct {
+		url      string
+		gateways []string
+		want     []string
+	}{
+		{"https://example.com/a.png", nil, []string{"https://example.com/a.png"}},
+		{"https://example.com/a.png", []string{"https://gateway.example/ipfs"}, []string{"https://example.com/a.png"}},
+		{"ipfs://cid/a.png", nil, []string{"https://ipfs.io/ipfs/cid/a.png"}},
+		{"ipfs://ipfs/cid", []string{"https://gateway.example/ipfs"}, []string{"https://gateway.example/ipfs/cid"}},
+		{"ipfs://cid", []string{"https://one.example/ipfs/", "https://two.example/ipfs"}, []string{"https://one.example/ipfs/cid", "https://two.example/ipfs/cid"}},
+	}
+
+	for _, test := range tests {
+		cfg := &options{ipfsGateways: test.gateways}
+		if got := resolveURLs(test.url, cfg); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("resolveURLs(%q) with gateways %q = %q, want %q", test.url, test.gateways, got, test.want)
+		}
+	}
+}
+
+func TestWithIPFSGateways(t *testing.T) {
+	var downRequests int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downRequests++
+		http.Error(w, "unavailable", http.StatusInternalServerError)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ipfs/cid/media.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer up.Close()
+
+	a := NewOffline(WithIPFSGateways([]string{down.URL + "/ipfs/", up.URL + "/ipfs/"}))
+	meta := &Metadata{MediaURL: "ipfs://cid/media.png"}
+	data, contentType, err := a.FetchMedia(context.Background(), meta)
+	if err != nil {
+		t.Fatalf("FetchMedia(WithIPFSGateways()) failed with error: %s, want success", err)
+	}
+	if string(data) != "png" || contentType != "image/png" {
+		t.Errorf("FetchMedia(WithIPFSGateways()) = %q, %q, want media from second gateway", data, contentType)
+	}
+	if downRequests != 1 {
+		t.Errorf("first gateway received %d requests, want 1", downRequests)
+	}
+
+	_, _, err = a.FetchMedia(context.Background(), meta, WithIPFSGateways([]string{down.URL + "/ipfs/", down.URL + "/mirror/"}))
+	if err == nil || !strings.Contains(err.Error(), "/ipfs/cid/media.png") || !strings.Contains(err.Error(), "/mirror/cid/media.png") {
+		t.Errorf("FetchMedia() with every gateway down = %v, want error naming each gateway", err)
+	}
+}
+
+func TestFetchWithFallback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/arc3.json" {
+			w.Write([]byte(`{"name":"Creature","description":"from arc3","image":"ipfs://image"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(&Metadata{Standard: "arc69", Description: "from url"})
+	}))
+	defer srv.Close()
+
+	idx := newFakeIndexer(t)
+	idx.addAsset(1, models.AssetParams{})
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "from note"})
+	idx.addAsset(2, models.AssetParams{Url: srv.URL + "/metadata.json#arc3"})
+	idx.addAsset(3, models.AssetParams{})
+	idx.addAsset(4, models.AssetParams{Url: srv.URL + "/arc3.json#arc3"})
+	a := New(nil, idx.client(t))
+
+	tests := []struct {
+		assetID     uint64
+		description string
+		source      string
+	}{
+		{1, "from note", SourceNote},
+		{2, "from url", SourceURL},
+		{4, "from arc3", SourceURL},
+	}
+	for _, test := range tests {
+		meta, source, err := a.FetchWithFallback(context.Background(), test.assetID)
+		if err != nil {
+			t.Errorf("FetchWithFallback(%d) failed with error: %s, want success", test.assetID, err)
+			continue
+		}
+		if meta.Description != test.description || source != test.source {
+			t.Errorf("FetchWithFallback(%d) = %q from %q, want %q from %q", test.assetID, meta.Description, source, test.description, test.source)
+		}
+	}
+
+	if meta, _, err := a.FetchWithFallback(context.Background(), 4); err != nil || meta.MediaURL != "ipfs://image" {
+		t.Errorf("FetchWithFallback(4) of ARC3 metadata = %+v, %v, want media URL \"ipfs://image\"", meta, err)
+	}
+
+	if _, _, err := a.FetchWithFallback(context.Background(), 3); err == nil {
+		t.Errorf("FetchWithFallback(3) succeeded for asset with no note or URL, want error")
+	}
+}
+
+func TestResolveAssetURL(t *testing.T) {
+	reserve := crypto.GenerateAccount().Address.String()
+
+	got, err := resolveAssetURL(models.AssetParams{Url: "template-ipfs://{ipfscid:1:raw:reserve:sha2-256}/meta.json", Reserve: reserve})
+	if err != nil {
+		t.Fatalf("resolveAssetURL() failed with error: %s, want success", err)
+	}
+	if !strings.HasPrefix(got, "ipfs://bafkrei") || !strings.HasSuffix(got, "/meta.json") {
+		t.Errorf("resolveAssetURL() = %q, want CIDv1 raw ipfs:// URL", got)
+	}
+
+	got, err = resolveAssetURL(models.AssetParams{Url: "template-ipfs://{ipfscid:0:dag-pb:reserve:sha2-256}", Reserve: reserve})
+	if err != nil {
+		t.Fatalf("resolveAssetURL() failed with error: %s, want success", err)
+	}
+	if !strings.HasPrefix(got, "ipfs://Qm") {
+		t.Errorf("resolveAssetURL() = %q, want CIDv0 ipfs:// URL", got)
+	}
+
+	got, err = resolveAssetURL(models.AssetParams{Url: "ipfs://cid#arc3"})
+	if err != nil || got != "ipfs://cid" {
+		t.Errorf("resolveAssetURL() = %q, %v, want \"ipfs://cid\"", got, err)
+	}
+}
+
+func TestMetadataSetMimeFromContent(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	mp4 := []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp41isom")
+
+	meta := &Metadata{}
+	meta.SetMimeFromContent(png, false)
+	if meta.MimeType != "image/png" {
+		t.Errorf("SetMimeFromContent(png) set %q, want \"image/png\"", meta.MimeType)
+	}
+
+	meta.SetMimeFromContent(mp4, false)
+	if meta.MimeType != "image/png" {
+		t.Errorf("SetMimeFromContent(mp4, false) set %q, want existing \"image/png\" kept", meta.MimeType)
+	}
+
+	meta.SetMimeFromContent(mp4, true)
+	if meta.MimeType != "video/mp4" {
+		t.Errorf("SetMimeFromContent(mp4, true) set %q, want \"video/mp4\"", meta.MimeType)
+	}
+}
+
+func TestFetchMedia(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png bytes"))
+	}))
+	defer srv.Close()
+
+	a := New(nil, nil)
+	m := &Metadata{MediaURL: srv.URL + "/media.png"}
+
+	data, contentType, err := a.FetchMedia(context.Background(), m)
+	if err != nil {
+		t.Fatalf("FetchMedia() failed with error: %s, want success", err)
+	}
+	if string(data) != "png bytes" || contentType != "image/png" {
+		t.Errorf("FetchMedia() = %q, %q, want \"png bytes\", \"image/png\"", data, contentType)
+	}
+
+	if _, _, err := a.FetchMedia(context.Background(), m, WithMaxMediaSize(4)); err == nil {
+		t.Errorf("FetchMedia(WithMaxMediaSize(4)) succeeded, want error")
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.requests = append(rt.requests, req)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	rt := &recordingTransport{}
+	a := New(nil, nil, WithHTTPClient(&http.Client{Transport: rt}))
+	if err := a.ValidateMediaAccessible(context.Background(), &Metadata{MediaURL: srv.URL + "/media.png"}); err != nil {
+		t.Fatalf("ValidateMediaAccessible() failed with error: %s, want success", err)
+	}
+
+	if len(rt.requests) != 1 || rt.requests[0].URL.Path != "/media.png" {
+		t.Errorf("custom client made requests %v, want a single request to /media.png", rt.requests)
+	}
+}
+
+func TestFetchMerged(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{
+			"name": "Creature #1",
+			"description": "base",
+			"image": "ipfs://media",
+			"image_mimetype": "image/png",
+			"properties": {"artist": "alice", "stats": {"hp": 10, "mp": 5}},
+			"attributes": [{"trait_type": "Background", "value": "Blue"}, {"trait_type": "Hat", "value": "Cap"}]
+		}`))
+	}))
+	defer srv.Close()
+
+	idx := newFakeIndexer(t)
+	idx.addAsset(1, models.AssetParams{Url: srv.URL + "/metadata.json"})
+	idx.addMetadata(t, 1, 10, &Metadata{
+		Standard:   "arc69",
+		Properties: map[string]interface{}{"stats": map[string]interface{}{"hp": 20}},
+		Attributes: []Attribute{{TraitType: "Hat", Value: "Crown"}, {TraitType: "Level", Value: "2"}},
+	})
+	idx.addAsset(2, models.AssetParams{})
+	idx.addMetadata(t, 2, 10, &Metadata{Standard: "arc69"})
+	a := New(nil, idx.client(t))
+
+	got, err := a.FetchMerged(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchMerged() failed with error: %s, want success", err)
+	}
+	want := &Metadata{
+		Standard:    "arc69",
+		Description: "base",
+		MediaURL:    "ipfs://media",
+		MimeType:    "image/png",
+		Properties:  map[string]interface{}{"name": "Creature #1", "artist": "alice", "stats": map[string]interface{}{"hp": json.Number("20"), "mp": json.Number("5")}},
+		Attributes: []Attribute{
+			{TraitType: "Background", Value: "Blue"},
+			{TraitType: "Hat", Value: "Crown"},
+			{TraitType: "Level", Value: "2"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FetchMerged() = %+v, want %+v", got, want)
+	}
+
+	if _, err := a.FetchMerged(context.Background(), 2); err == nil {
+		t.Errorf("FetchMerged() of asset without URL succeeded, want error")
+	}
+}
+
+func TestVerifyMimeType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("VerifyMimeType() sent %s request, want HEAD", r.Method)
+		}
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+		case "/video.mp4":
+			w.Header().Set("Content-Type", "video/mp4; codecs=avc1")
+		case "/unknown":
+			w.Header()["Content-Type"] = nil
+		}
+	}))
+	defer srv.Close()
+	a := NewOffline()
+
+	tests := []struct {
+		path, mimeType string
+		want           bool
+	}{
+		{"/image.png", "image/png", true},
+		{"/image.png", "IMAGE/PNG", true},
+		{"/video.mp4", "video/mp4", true},
+		{"/video.mp4", "image/png", false},
+	}
+	for _, test := range tests {
+		m := &Metadata{MediaURL: srv.URL + test.path, MimeType: test.mimeType}
+		got, err := a.VerifyMimeType(context.Background(), m)
+		if err != nil {
+			t.Errorf("VerifyMimeType(%s, %s) failed with error: %s, want success", test.path, test.mimeType, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("VerifyMimeType(%s, %s) = %t, want %t", test.path, test.mimeType, got, test.want)
+		}
+	}
+
+	if _, err := a.VerifyMimeType(context.Background(), &Metadata{MediaURL: srv.URL + "/unknown", MimeType: "image/png"}); err == nil {
+		t.Errorf("VerifyMimeType() without content type succeeded, want error")
+	}
+	if _, err := a.VerifyMimeType(context.Background(), &Metadata{MediaURL: srv.URL + "/image.png"}); err == nil {
+		t.Errorf("VerifyMimeType() without declared mime type succeeded, want error")
+	}
+}
+
+func TestMetadataInferMimeType(t *testing.T) {
+	tests := []struct {
+		meta *Metadata
+		want string
+	}{
+		{&Metadata{MimeType: "image/gif", MediaURL: "ipfs://cid/image.png"}, "image/gif"},
+		{&Metadata{MediaURL: "ipfs://cid/image.PNG"}, "image/png"},
+		{&Metadata{MediaURL: "https://example.com/video.mp4?v=1"}, "video/mp4"},
+		{&Metadata{MediaURL: "ipfs://cid"}, ""},
+	}
+	for _, test := range tests {
+		if got := test.meta.InferMimeType(); got != test.want {
+			t.Errorf("InferMimeType(%+v) = %q, want %q", *test.meta, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,261 @@
+package arc69
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// gzipMagic is the header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maxDecodedNoteSize is the largest metadata, once decompressed, that is parsed.
+// It bounds the memory a small gzip-compressed note can expand to.
+const maxDecodedNoteSize = 1 << 20
+
+// maxNoteDepth is the deepest nesting of JSON objects and arrays that is parsed.
+const maxNoteDepth = 64
+
+// NoteEncoding is the encoding of the metadata held in a note.
+type NoteEncoding int
+
+const (
+	// EncodingAuto detects the encoding of each note, which is the default.
+	EncodingAuto NoteEncoding = iota
+	// EncodingRaw reads notes as plain JSON.
+	EncodingRaw
+	// EncodingBase64 reads notes as base64-encoded JSON.
+	EncodingBase64
+	// EncodingGzip reads notes as gzip-compressed JSON.
+	EncodingGzip
+)
+
+// encodeNote converts the JSON encoded metadata into the bytes stored in the
+// transaction note.
+func encodeNote(data []byte, cfg *options) ([]byte, error) {
+	if !cfg.compressNotes {
+		return append(append([]byte(nil), cfg.notePrefix...), data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.Write(cfg.notePrefix)
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("unable to compress note: %s", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("unable to compress note: %s", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeNote converts the bytes stored in a transaction note back into JSON
+// encoded metadata. The configured note prefix is stripped if the note starts
+// with it. Unless an encoding is configured, notes are then detected in the
+// following order: (1) plain JSON, (2) base64-encoded JSON, (3) gzip-compressed
+// JSON. A note matching none of them is returned unchanged so that parsing it
+// reports the JSON error.
+func decodeNote(note []byte, cfg *options) ([]byte, error) {
+	if len(cfg.notePrefix) > 0 {
+		note = bytes.TrimPrefix(note, cfg.notePrefix)
+	}
+
+	switch cfg.noteEncoding {
+	case EncodingRaw:
+		return note, nil
+	case EncodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(note)))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode base64 note: %s", err)
+		}
+		return decoded, nil
+	case EncodingGzip:
+		return gunzip(note)
+	}
+
+	if json.Valid(note) {
+		return note, nil
+	}
+
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(note))); err == nil && json.Valid(decoded) {
+		return decoded, nil
+	}
+
+	if bytes.HasPrefix(note, gzipMagic) {
+		data, err := gunzip(note)
+		if err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+
+	return note, nil
+}
+
+// Helper function to decompress a gzip stream.
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress note: %s", err)
+	}
+	defer zr.Close()
+
+	out, err := io.ReadAll(io.LimitReader(zr, maxDecodedNoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress note: %s", err)
+	}
+	if len(out) > maxDecodedNoteSize {
+		return nil, fmt.Errorf("unable to decompress note: exceeds %d bytes", maxDecodedNoteSize)
+	}
+
+	return out, nil
+}
+
+// ParseNote parses the metadata held in a transaction note like Fetch does for
+// the latest note of an asset, so that notes obtained elsewhere can be read
+// without an indexer. Only the options affecting how notes are read, such as
+// WithNotePrefix, WithNoteEncoding and WithStrictDecoding, have an effect. An
+// error is returned if the note does not hold valid ARC69 metadata.
+func ParseNote(note []byte, opts ...Option) (*Metadata, error) {
+	var cfg options
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return parseValidNote(note, &cfg)
+}
+
+// parseValidNote parses a note like parseNote and checks that it holds valid
+// ARC69 metadata.
+func parseValidNote(note []byte, cfg *options) (*Metadata, error) {
+	meta, err := parseNote(note, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !meta.IsValid() {
+		return nil, fmt.Errorf("invalid metadata")
+	}
+	return meta, nil
+}
+
+// parseNote decodes a transaction note and parses the metadata it holds. Numbers
+// in properties are kept as json.Number so that large integers keep their
+// precision. Unknown fields are rejected when strict decoding is configured.
+// Metadata nested deeper than maxNoteDepth is rejected before decoding.
+//
+// Some early assets store properties as a JSON array rather than an object. Such
+// notes are accepted and the elements of the array are kept in Properties keyed
+// by their decimal index (ex. "0"), so that Property("0.name") still reaches
+// them. Encoding the metadata again writes properties back as an array.
+func parseNote(note []byte, cfg *options) (*Metadata, error) {
+	data, err := decodeNote(note, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return parseMetadata(data, cfg)
+}
+
+// parseMetadata parses JSON encoded metadata, already decoded from its note, like
+// parseNote.
+func parseMetadata(data []byte, cfg *options) (*Metadata, error) {
+	if depth := jsonDepth(data); depth > maxNoteDepth {
+		return nil, fmt.Errorf("unable to parse metadata: nested %d levels deep, more than %d", depth, maxNoteDepth)
+	}
+
+	var meta Metadata
+	err := decodeMetadata(data, cfg, &meta)
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field == "properties" && typeErr.Value == "array" {
+		var legacy struct {
+			Metadata
+			Properties []interface{} `json:"properties"`
+		}
+		if err = decodeMetadata(data, cfg, &legacy); err == nil {
+			meta = legacy.Metadata
+			meta.arrayProperties = true
+			meta.Properties = make(map[string]interface{}, len(legacy.Properties))
+			for i, v := range legacy.Properties {
+				meta.Properties[strconv.Itoa(i)] = v
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse metadata: %s", err)
+	}
+
+	return &meta, nil
+}
+
+// Helper function that decodes metadata into v, keeping numbers as json.Number and
+// rejecting unknown fields, including those of attributes, when strict decoding is
+// configured.
+func decodeMetadata(data []byte, cfg *options, v interface{}) error {
+	dec := newNumberDecoder(bytes.NewReader(data))
+	if cfg.strictDecoding {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if cfg.strictDecoding {
+		return checkAttributeFields(data)
+	}
+	return nil
+}
+
+// Helper function that reports an error if an attribute in the metadata holds an
+// unknown field. Attribute decodes itself, so the decoder of the metadata does not
+// check its fields.
+func checkAttributeFields(data []byte) error {
+	var raw struct {
+		Attributes []json.RawMessage `json:"attributes"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	for i, attr := range raw.Attributes {
+		dec := json.NewDecoder(bytes.NewReader(attr))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&attributeJSON{}); err != nil {
+			return fmt.Errorf("attributes[%d]: %s", i, err)
+		}
+	}
+	return nil
+}
+
+// Helper function that returns the deepest nesting of objects and arrays in data,
+// skipping over strings. data does not need to be valid JSON.
+func jsonDepth(data []byte) int {
+	depth, deepest := 0, 0
+	inString, escaped := false, false
+	for _, c := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			depth++
+			if depth > deepest {
+				deepest = depth
+			}
+		case c == '}' || c == ']':
+			depth--
+		}
+	}
+	return deepest
+}
+
+// Helper function that returns a JSON decoder reading from r that decodes numbers
+// as json.Number, like every decode of metadata in this package.
+func newNumberDecoder(r io.Reader) *json.Decoder {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	return dec
+}
@@ -0,0 +1,44 @@
+//go:build go1.18
+// +build go1.18
+
+package arc69
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func FuzzParseNote(f *testing.F) {
+	var gzipped bytes.Buffer
+	zw := gzip.NewWriter(&gzipped)
+	zw.Write([]byte(`{"standard":"arc69","properties":{"a":[1,2,3]}}`))
+	zw.Close()
+
+	seeds := []string{
+		`{"standard":"arc69"}`,
+		`{"standard":"arc69","properties":{"a":{"b":{"c":"d"}}},"attributes":[{"trait_type":"Hat","value":3}]}`,
+		base64.StdEncoding.EncodeToString([]byte(`{"standard":"arc69"}`)),
+		gzipped.String(),
+		strings.Repeat("[", 10000) + strings.Repeat("]", 10000),
+		`{"standard":"arc69","properties":` + strings.Repeat(`{"a":`, 1000) + `1` + strings.Repeat("}", 1000) + `}`,
+		`{"standard":"arc69","properties":{"a":[` + strings.Repeat("0,", 100000) + `0]}}`,
+		`{"standard":"arc69","description":"\"{[{["}`,
+		`{"standard":`,
+		`{"i":0,"n":100000000000000,"d":""}`,
+		"\x1f\x8b",
+		"",
+	}
+	for _, seed := range seeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, note []byte) {
+		meta, err := ParseNote(note)
+		if (meta == nil) == (err == nil) {
+			t.Errorf("ParseNote(%q) = %+v, %v, want either metadata or an error", note, meta, err)
+		}
+	})
+}
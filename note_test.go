@@ -0,0 +1,276 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNoteCompressionRoundTrip(t *testing.T) {
+	meta := &Metadata{
+		Standard:    "arc69",
+		Description: "compressed",
+		Properties:  map[string]interface{}{"a": "aa"},
+		Attributes:  []Attribute{{TraitType: "Background", Value: "Blue"}},
+	}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+
+	note, err := encodeNote(data, &options{compressNotes: true})
+	if err != nil {
+		t.Fatalf("encodeNote() failed with error: %s, want success", err)
+	}
+	if !bytes.HasPrefix(note, gzipMagic) {
+		t.Fatalf("encodeNote() = %x, want gzip stream", note)
+	}
+
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 10, note)
+	got, err := New(nil, idx.client(t)).Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if !reflect.DeepEqual(got, meta) {
+		t.Errorf("Fetch() = %+v, want %+v", got, meta)
+	}
+}
+
+func TestFetchUncompressedNote(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Description: "plain"}
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, meta)
+
+	got, err := New(nil, idx.client(t)).Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if !reflect.DeepEqual(got, meta) {
+		t.Errorf("Fetch() = %+v, want %+v", got, meta)
+	}
+}
+
+func TestParseNoteEncodings(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Description: "encoded", Properties: map[string]interface{}{"a": "aa"}}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+	gzipped, err := encodeNote(data, &options{compressNotes: true})
+	if err != nil {
+		t.Fatalf("encodeNote() failed with error: %s", err)
+	}
+
+	notes := map[string][]byte{
+		"plain":  data,
+		"base64": []byte(base64.StdEncoding.EncodeToString(data)),
+		"gzip":   gzipped,
+	}
+	for name, note := range notes {
+		got, err := parseNote(note, &options{})
+		if err != nil {
+			t.Errorf("parseNote(%s) failed with error: %s, want success", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, meta) {
+			t.Errorf("parseNote(%s) = %+v, want %+v", name, got, meta)
+		}
+	}
+
+	if _, err := parseNote([]byte("not metadata"), &options{}); err == nil {
+		t.Errorf("parseNote() of plain text succeeded, want error")
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addNote(1, 10, []byte(`{"standard":"arc69","medial_url":"ipfs://media"}`))
+	a := New(nil, idx.client(t))
+
+	if _, err := a.Fetch(context.Background(), 1); err != nil {
+		t.Errorf("Fetch() failed with error: %s, want success", err)
+	}
+
+	_, err := a.Fetch(context.Background(), 1, WithStrictDecoding())
+	if err == nil || !strings.Contains(err.Error(), "medial_url") {
+		t.Errorf("Fetch(WithStrictDecoding()) = %v, want error naming medial_url", err)
+	}
+
+	note := []byte(`{"standard":"arc69","attributes":[{"trait_type":"a","valeu":"b"}]}`)
+	if _, err := ParseNote(note); err != nil {
+		t.Errorf("ParseNote() of unknown attribute field failed with error: %s, want success", err)
+	}
+	_, err = ParseNote(note, WithStrictDecoding())
+	if err == nil || !strings.Contains(err.Error(), "valeu") {
+		t.Errorf("ParseNote(WithStrictDecoding()) of unknown attribute field = %v, want error naming valeu", err)
+	}
+}
+
+func TestNotePrefix(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	prefix := []byte("arc69:j")
+	a := New(algod.client(t), idx.client(t), WithNotePrefix(prefix))
+
+	meta := &Metadata{Standard: "arc69", Description: "prefixed"}
+	if err := a.Update(context.Background(), account, 1, meta); err != nil {
+		t.Fatalf("Update(WithNotePrefix()) failed with error: %s, want success", err)
+	}
+	if note := algod.sentTxns()[0].Txn.Note; !bytes.HasPrefix(note, prefix) {
+		t.Errorf("Update(WithNotePrefix()) note = %q, want prefix %q", note, prefix)
+	}
+
+	got, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch(WithNotePrefix()) failed with error: %s, want success", err)
+	}
+	if !got.Equal(meta) {
+		t.Errorf("Fetch(WithNotePrefix()) = %+v, want %+v", got, meta)
+	}
+
+	legacy := newFakeIndexer(t)
+	legacy.addMetadata(t, 2, 10, &Metadata{Standard: "arc69", Description: "legacy"})
+	got, err = New(nil, legacy.client(t), WithNotePrefix(prefix)).Fetch(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Fetch(WithNotePrefix()) of legacy note failed with error: %s, want success", err)
+	}
+	if got.Description != "legacy" {
+		t.Errorf("Fetch(WithNotePrefix()) of legacy note = %q, want \"legacy\"", got.Description)
+	}
+}
+
+func TestWithNoteEncoding(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Description: "encoded"}
+	data, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("json.Marshal() failed with error: %s", err)
+	}
+	gzipped, err := encodeNote(data, &options{compressNotes: true})
+	if err != nil {
+		t.Fatalf("encodeNote() failed with error: %s", err)
+	}
+
+	notes := map[NoteEncoding][]byte{
+		EncodingRaw:    data,
+		EncodingBase64: []byte(base64.StdEncoding.EncodeToString(data)),
+		EncodingGzip:   gzipped,
+	}
+	for enc, note := range notes {
+		got, err := parseNote(note, &options{noteEncoding: enc})
+		if err != nil {
+			t.Errorf("parseNote(WithNoteEncoding(%d)) failed with error: %s, want success", enc, err)
+		} else if !reflect.DeepEqual(got, meta) {
+			t.Errorf("parseNote(WithNoteEncoding(%d)) = %+v, want %+v", enc, got, meta)
+		}
+
+		for other, otherNote := range notes {
+			if other == enc {
+				continue
+			}
+			if _, err := parseNote(otherNote, &options{noteEncoding: enc}); err == nil {
+				t.Errorf("parseNote(WithNoteEncoding(%d)) of note encoded with %d succeeded, want error", enc, other)
+			}
+		}
+	}
+}
+
+func TestParseNote(t *testing.T) {
+	got, err := ParseNote([]byte(`{"standard":"arc69","description":"offline"}`))
+	if err != nil {
+		t.Fatalf("ParseNote() failed with error: %s, want success", err)
+	}
+	if want := (&Metadata{Standard: "arc69", Description: "offline"}); !got.Equal(want) {
+		t.Errorf("ParseNote() = %+v, want %+v", got, want)
+	}
+
+	got, err = ParseNote([]byte(`arc69:{"standard":"arc69"}`), WithNotePrefix([]byte("arc69:")))
+	if err != nil || !got.IsValid() {
+		t.Errorf("ParseNote(WithNotePrefix()) = %+v, %v, want valid metadata", got, err)
+	}
+
+	for _, note := range []string{`{"standard":`, `{"standard":"arc3"}`, "gm"} {
+		if got, err := ParseNote([]byte(note)); err == nil {
+			t.Errorf("ParseNote(%q) = %+v, want error", note, got)
+		}
+	}
+}
+
+func TestParseNoteLimits(t *testing.T) {
+	deep := `{"standard":"arc69","properties":` + strings.Repeat(`{"a":`, maxNoteDepth) + `1` + strings.Repeat("}", maxNoteDepth) + `}`
+	if _, err := ParseNote([]byte(deep)); err == nil {
+		t.Errorf("ParseNote() of metadata nested %d levels deep succeeded, want error", maxNoteDepth+1)
+	}
+
+	brackets := `{"standard":"arc69","description":"` + strings.Repeat("[", 2*maxNoteDepth) + `"}`
+	if _, err := ParseNote([]byte(brackets)); err != nil {
+		t.Errorf("ParseNote() with brackets inside a string failed with error: %s, want success", err)
+	}
+
+	bomb, err := encodeNote([]byte(`{"standard":"arc69","description":"`+strings.Repeat("a", maxDecodedNoteSize)+`"}`), &options{compressNotes: true})
+	if err != nil {
+		t.Fatalf("encodeNote() failed with error: %s", err)
+	}
+	if _, err := ParseNote(bomb); err == nil {
+		t.Errorf("ParseNote() of note decompressing past %d bytes succeeded, want error", maxDecodedNoteSize)
+	}
+}
+
+func TestParseNoteLegacyArrayProperties(t *testing.T) {
+	note := `{"standard":"arc69","description":"legacy","properties":[{"name":"first"},{"name":"second"},"loose"]}`
+	for _, strict := range []bool{false, true} {
+		got, err := parseNote([]byte(note), &options{strictDecoding: strict})
+		if err != nil {
+			t.Fatalf("parseNote(strict=%t) failed with error: %s, want success", strict, err)
+		}
+		if got.Description != "legacy" {
+			t.Errorf("parseNote(strict=%t) description = %q, want \"legacy\"", strict, got.Description)
+		}
+		checkProperty("1.name", "second", got, t)
+		checkProperty("2", "loose", got, t)
+
+		data, err := got.JSON()
+		if err != nil {
+			t.Fatalf("JSON() failed with error: %s", err)
+		}
+		if want := `{"standard":"arc69","description":"legacy","external_url":"","media_url":"","properties":[{"name":"first"},{"name":"second"},"loose"],"mime_type":"","attributes":null}`; string(data) != want {
+			t.Errorf("JSON() of legacy array properties = %s, want %s", data, want)
+		}
+		if ok, err := got.RoundTripsCleanly(); !ok || err != nil {
+			t.Errorf("RoundTripsCleanly() of legacy array properties = %t, %v, want true, nil", ok, err)
+		}
+	}
+
+	meta, err := parseNote([]byte(note), &options{})
+	if err != nil {
+		t.Fatalf("parseNote() failed with error: %s, want success", err)
+	}
+	if err := meta.SetProperty("1.name", "changed"); err != nil {
+		t.Fatalf("SetProperty() failed with error: %s", err)
+	}
+	data, err := meta.JSON()
+	if err != nil {
+		t.Fatalf("JSON() failed with error: %s", err)
+	}
+	if !strings.Contains(string(data), `"properties":[{"name":"first"},{"name":"changed"},"loose"]`) {
+		t.Errorf("JSON() of edited legacy array properties = %s, want properties array", data)
+	}
+	if err := meta.DeleteProperty("0", false); err != nil {
+		t.Fatalf("DeleteProperty() failed with error: %s", err)
+	}
+	if data, err = meta.JSON(); err != nil {
+		t.Fatalf("JSON() failed with error: %s", err)
+	}
+	if !strings.Contains(string(data), `"properties":{"1":{"name":"changed"},"2":"loose"}`) {
+		t.Errorf("JSON() of legacy array properties missing index 0 = %s, want properties object", data)
+	}
+
+	if _, err := parseNote([]byte(`{"standard":"arc69","properties":"flat"}`), &options{}); err == nil {
+		t.Errorf("parseNote() with string properties succeeded, want error")
+	}
+}
@@ -0,0 +1,53 @@
+package arc69
+
+import (
+	"context"
+	"time"
+)
+
+// Observer is notified around each network call made to the indexer and algod.
+// op names the call, for instance "indexer.LookupAssetTransactions" or
+// "algod.SendRawTransaction". Each poll made while waiting for a transaction to
+// be confirmed is reported as its own call. Observers may be called from several
+// goroutines at once.
+type Observer interface {
+	// OnRequest is called before the call is made.
+	OnRequest(op string)
+	// OnResponse is called once the call returns, with its duration and error.
+	OnResponse(op string, dur time.Duration, err error)
+}
+
+// observe runs the network call fn named op, reporting it to the configured
+// Observer if there is one. ErrClosed is returned without making the call if the
+// ARC69 object was closed. The call waits for the limiter set by WithRateLimit
+// first, and if ctx has no deadline, it is bounded by the timeout set by
+// WithDefaultTimeout.
+func observe(ctx context.Context, cfg *options, op string, fn func(context.Context) error) error {
+	select {
+	case <-cfg.closed:
+		return ErrClosed
+	default:
+	}
+
+	if cfg.limiter != nil {
+		if err := cfg.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, ok := ctx.Deadline(); !ok && cfg.defaultTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.defaultTimeout)
+		defer cancel()
+	}
+
+	if cfg.observer == nil {
+		return fn(ctx)
+	}
+
+	cfg.observer.OnRequest(op)
+	start := time.Now()
+	err := fn(ctx)
+	cfg.observer.OnResponse(op, time.Since(start), err)
+	return err
+}
@@ -0,0 +1,192 @@
+package arc69
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+)
+
+type recordingObserver struct {
+	mu        sync.Mutex
+	requests  []string
+	responses []string
+	durations []time.Duration
+	errs      []error
+}
+
+func (o *recordingObserver) OnRequest(op string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, op)
+}
+
+func (o *recordingObserver) OnResponse(op string, dur time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.responses = append(o.responses, op)
+	o.durations = append(o.durations, dur)
+	o.errs = append(o.errs, err)
+}
+
+func TestObserver(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69"})
+	obs := &recordingObserver{}
+	a := New(nil, idx.client(t), WithObserver(obs))
+
+	if _, err := a.Fetch(context.Background(), 1); err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if _, err := a.Fetch(context.Background(), 2); err == nil {
+		t.Fatalf("Fetch() of unknown asset succeeded, want error")
+	}
+
+	want := []string{"indexer.LookupAssetTransactions", "indexer.LookupAssetTransactions"}
+	if !reflect.DeepEqual(obs.requests, want) || !reflect.DeepEqual(obs.responses, want) {
+		t.Errorf("observer recorded requests %q and responses %q, want %q", obs.requests, obs.responses, want)
+	}
+	if len(obs.durations) != 2 || obs.durations[0] <= 0 {
+		t.Errorf("observer recorded durations %v, want positive durations", obs.durations)
+	}
+	if obs.errs[0] != nil {
+		t.Errorf("observer recorded error %v for successful call, want nil", obs.errs[0])
+	}
+}
+
+func TestObserverUpdate(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	algod.unconfirmed = true
+	obs := &recordingObserver{}
+	a := New(algod.client(t), idx.client(t), WithObserver(obs))
+
+	if err := a.Update(context.Background(), account, 1, &Metadata{Standard: "arc69"}); err != nil {
+		t.Fatalf("Update() failed with error: %s, want success", err)
+	}
+
+	// The confirmation is only found by the indexer once algod gave up waiting.
+	want := []string{"indexer.LookupAssetByID", "algod.SuggestedParams", "algod.SendRawTransaction", "algod.Status"}
+	for i := 0; i < 4; i++ {
+		want = append(want, "algod.PendingTransactionInformation", "algod.StatusAfterBlock")
+	}
+	want = append(want, "indexer.LookupTransaction")
+	if !reflect.DeepEqual(obs.requests, want) {
+		t.Errorf("observer recorded requests %q, want %q", obs.requests, want)
+	}
+}
+
+func TestWithDefaultTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	c, err := indexer.MakeClient(server.URL, "")
+	if err != nil {
+		t.Fatalf("indexer.MakeClient() failed with error: %s", err)
+	}
+	a := New(nil, c, WithDefaultTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	_, err = a.Fetch(context.Background(), 1)
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Fetch() with slow indexer = %v, want deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Fetch() with slow indexer returned after %s, want about 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if _, err := a.Fetch(ctx, 1); err == nil {
+		t.Errorf("Fetch() with slow indexer succeeded, want error")
+	}
+	if elapsed := time.Since(start); elapsed < 300*time.Millisecond {
+		t.Errorf("Fetch() with context deadline returned after %s, want the context deadline kept", elapsed)
+	}
+}
+
+func TestUpdateHonorsContext(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	algod.stallPending = true
+	a := New(algod.client(t), idx.client(t), WithDefaultTimeout(50*time.Millisecond))
+
+	start := time.Now()
+	err := a.Update(context.Background(), account, 1, &Metadata{Standard: "arc69"})
+	if err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("Update() with stalled confirmation = %v, want deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Update() with stalled confirmation returned after %s, want about 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := New(algod.client(t), idx.client(t)).Update(ctx, account, 1, &Metadata{Standard: "arc69"}); err == nil {
+		t.Errorf("Update() canceled while waiting for confirmation succeeded, want error")
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	idx := newFakeIndexer(t)
+	for id := uint64(1); id <= 5; id++ {
+		idx.addMetadata(t, id, 10, &Metadata{Standard: "arc69"})
+	}
+	a := New(nil, idx.client(t), WithRateLimit(20))
+
+	// The limiter allows one call at once, then one call every 50ms.
+	start := time.Now()
+	if _, err := a.BatchFetch(context.Background(), []uint64{1, 2, 3, 4, 5}); err != nil {
+		t.Fatalf("BatchFetch(WithRateLimit()) failed with error: %s, want success", err)
+	}
+	if elapsed, want := time.Since(start), 200*time.Millisecond; elapsed < want {
+		t.Errorf("BatchFetch(WithRateLimit(20)) of 5 assets took %s, want at least %s", elapsed, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := a.Fetch(ctx, 1); err == nil {
+		t.Errorf("Fetch(WithRateLimit()) with canceled context succeeded, want error")
+	}
+
+	for _, rps := range []float64{0, -1} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		if _, err := a.BatchFetch(ctx, []uint64{1, 2, 3, 4, 5}, WithRateLimit(rps)); err != nil {
+			t.Errorf("BatchFetch(WithRateLimit(%v)) failed with error: %s, want no rate limit", rps, err)
+		}
+		cancel()
+	}
+}
+
+func TestWithRateLimitConfirmation(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	algod.unconfirmed = true
+	obs := &recordingObserver{}
+	a := New(algod.client(t), idx.client(t), WithRateLimit(50), WithObserver(obs))
+
+	start := time.Now()
+	if err := a.Update(context.Background(), account, 1, &Metadata{Standard: "arc69"}); err != nil {
+		t.Fatalf("Update(WithRateLimit()) failed with error: %s, want success", err)
+	}
+
+	// Every call, including each confirmation poll, waits 20ms for the limiter.
+	calls := len(obs.requests)
+	if elapsed, want := time.Since(start), time.Duration(calls-1)*20*time.Millisecond; elapsed < want {
+		t.Errorf("Update(WithRateLimit(50)) making %d calls took %s, want at least %s", calls, elapsed, want)
+	}
+}
@@ -0,0 +1,276 @@
+package arc69
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/client/v2/indexer"
+	"golang.org/x/time/rate"
+)
+
+// Option configures the behavior of an ARC69 object. Options passed to New apply
+// to every call, while options passed to an individual method apply to that call
+// only and take precedence over the ones given to New.
+type Option func(*options)
+
+// options holds the configuration assembled from a list of Options.
+type options struct {
+	algodClient     *algod.Client
+	indexerClient   *indexer.Client
+	compressNotes   bool
+	ipfsGateways    []string
+	cacheSize       int
+	cacheTTL        time.Duration
+	rekeyTo         string
+	strictDecoding  bool
+	latestOnly      bool
+	maxMediaSize    int64
+	concurrency     int
+	validityWindow  uint64
+	httpClient      *http.Client
+	observer        Observer
+	rawTransactions bool
+	notePrefix      []byte
+	manager         *string
+	reserve         *string
+	freeze          *string
+	clawback        *string
+	noteEncoding    NoteEncoding
+	defaultTimeout  time.Duration
+	limiter         *rate.Limiter
+	indexerRound    uint64
+	maxHolders      uint64
+	// closed is the done channel of the ARC69 object the options belong to.
+	closed <-chan struct{}
+}
+
+// WithClients overrides the algod and indexer clients used by a call, which lets a
+// single ARC69 object target multiple networks. A nil client keeps the one given
+// to New.
+func WithClients(algodClient *algod.Client, indexerClient *indexer.Client) Option {
+	return func(o *options) {
+		if algodClient != nil {
+			o.algodClient = algodClient
+		}
+		if indexerClient != nil {
+			o.indexerClient = indexerClient
+		}
+	}
+}
+
+// WithCompression makes Update gzip-compress the metadata before storing it in
+// the transaction note, which allows larger metadata to fit in a note. Fetch
+// always detects and decompresses gzip notes, so this option only affects writes.
+func WithCompression() Option {
+	return func(o *options) {
+		o.compressNotes = true
+	}
+}
+
+// WithIPFSGateway sets the HTTP gateway used to resolve ipfs:// URLs, for example
+// "https://ipfs.io/ipfs/". The IPFS path is appended to the gateway URL.
+func WithIPFSGateway(gateway string) Option {
+	return func(o *options) {
+		o.ipfsGateways = []string{gateway}
+	}
+}
+
+// WithIPFSGateways sets several HTTP gateways used to resolve ipfs:// URLs, tried
+// in order until one of them returns a 2xx response. It replaces any gateway set
+// by WithIPFSGateway.
+func WithIPFSGateways(gateways []string) Option {
+	return func(o *options) {
+		o.ipfsGateways = gateways
+	}
+}
+
+// WithCache enables an in-memory LRU cache of up to size Fetch results, each kept
+// for at most ttl (a ttl of 0 never expires entries). Only Fetch calls made
+// without per-call options use the cache. This option only has an effect when
+// passed to New.
+func WithCache(size int, ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheSize = size
+		o.cacheTTL = ttl
+	}
+}
+
+// WithRekeyTo makes the update transaction built by BuildUpdate, and submitted by
+// Update, rekey the sender account to address.
+func WithRekeyTo(address string) Option {
+	return func(o *options) {
+		o.rekeyTo = address
+	}
+}
+
+// WithStrictDecoding makes Fetch and the other methods parsing notes reject
+// metadata holding unknown fields (ex. a misspelled "medial_url"), at the top level
+// or in an attribute, instead of silently ignoring them. The error names the
+// unknown field.
+func WithStrictDecoding() Option {
+	return func(o *options) {
+		o.strictDecoding = true
+	}
+}
+
+// WithLatestOnly makes Fetch only consider the note of the latest acfg transaction
+// of an asset and return an error if it does not hold valid ARC69 metadata, rather
+// than scanning back to an older note that does.
+func WithLatestOnly() Option {
+	return func(o *options) {
+		o.latestOnly = true
+	}
+}
+
+// WithMaxMediaSize sets the largest media, in bytes, that FetchMedia downloads.
+func WithMaxMediaSize(n int64) Option {
+	return func(o *options) {
+		o.maxMediaSize = n
+	}
+}
+
+// WithConcurrency sets the number of updates UpdateMany submits at a time. The
+// default is one, which submits them sequentially.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		o.concurrency = n
+	}
+}
+
+// WithValidityWindow makes the update transaction built by BuildUpdate, and
+// submitted by Update, valid for rounds rounds after its first valid round
+// instead of the network's default. The protocol allows at most 1000 rounds.
+func WithValidityWindow(rounds uint64) Option {
+	return func(o *options) {
+		o.validityWindow = rounds
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to fetch media and metadata URLs, for
+// instance to go through a proxy or use custom TLS settings. The default client
+// times out after 30 seconds.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithObserver makes the ARC69 object report each network call it makes to the
+// indexer and algod to observer, for instance to record latency and error metrics.
+func WithObserver(observer Observer) Option {
+	return func(o *options) {
+		o.observer = observer
+	}
+}
+
+// WithRawTransactions makes FetchHistory and IterateHistory include the indexer's
+// transaction, with fields such as the fee and group ID, in each MetadataVersion.
+func WithRawTransactions() Option {
+	return func(o *options) {
+		o.rawTransactions = true
+	}
+}
+
+// WithNotePrefix makes Update start the note with prefix, for tools expecting a
+// content marker before the metadata. Notes starting with prefix have it stripped
+// when read, while notes without it are still read as usual.
+func WithNotePrefix(prefix []byte) Option {
+	return func(o *options) {
+		o.notePrefix = prefix
+	}
+}
+
+// WithManager makes the update transaction built by BuildUpdate, and submitted by
+// Update, set the manager address of the asset to address instead of keeping the
+// current one. An empty address clears the role, which makes the asset immutable.
+func WithManager(address string) Option {
+	return func(o *options) {
+		o.manager = &address
+	}
+}
+
+// WithReserve is like WithManager for the reserve address.
+func WithReserve(address string) Option {
+	return func(o *options) {
+		o.reserve = &address
+	}
+}
+
+// WithFreeze is like WithManager for the freeze address.
+func WithFreeze(address string) Option {
+	return func(o *options) {
+		o.freeze = &address
+	}
+}
+
+// WithClawback is like WithManager for the clawback address.
+func WithClawback(address string) Option {
+	return func(o *options) {
+		o.clawback = &address
+	}
+}
+
+// WithNoteEncoding makes notes be read with the given encoding instead of
+// detecting it, so that a note in any other encoding fails to parse. It has no
+// effect on the notes written by Update, see WithCompression.
+func WithNoteEncoding(enc NoteEncoding) Option {
+	return func(o *options) {
+		o.noteEncoding = enc
+	}
+}
+
+// WithDefaultTimeout bounds each call made to algod and the indexer by d when the
+// context passed to a method has no deadline. Contexts with a deadline are left
+// untouched.
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.defaultTimeout = d
+	}
+}
+
+// WithRateLimit throttles the calls made to algod and the indexer to rps calls per
+// second. The limiter is created once per call to WithRateLimit, so every method
+// and worker using the returned Option shares it, and waiting for it respects
+// the context passed to the method. An rps of 0 or less disables rate limiting.
+func WithRateLimit(rps float64) Option {
+	var limiter *rate.Limiter
+	if rps > 0 {
+		limiter = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+	return func(o *options) {
+		o.limiter = limiter
+	}
+}
+
+// WithIndexerRound makes Fetch and the history methods read the acfg transactions
+// of an asset as of the given round, ignoring those confirmed after it, so that
+// repeated reads return the same result even as the chain and the indexer
+// progress. The round is passed to the indexer as the max-round of its queries.
+// It pins the indexer's view for every call taking the option, which differs from
+// choosing a version by when it was confirmed: this package has no FetchAt, and
+// FetchHistory and FetchVersion pick among the versions the indexer reports, so
+// combined with WithIndexerRound they only see the versions confirmed by round.
+func WithIndexerRound(round uint64) Option {
+	return func(o *options) {
+		o.indexerRound = round
+	}
+}
+
+// WithMaxHolders bounds the number of holders FetchWithHolders counts, so that
+// counting the holders of popular assets does not page through all of them.
+func WithMaxHolders(n uint64) Option {
+	return func(o *options) {
+		o.maxHolders = n
+	}
+}
+
+// config returns the defaults of a with opts applied on top of them.
+func (a *ARC69) config(opts []Option) *options {
+	cfg := a.options
+	cfg.closed = a.done
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &cfg
+}
@@ -0,0 +1,63 @@
+package arc69
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// signatureProperty is the property holding the creator signature checked by
+// VerifyCreatorSignature.
+const signatureProperty = "signature"
+
+// VerifyCreatorSignature fetches the ARC69 metadata of an asset and reports
+// whether properties.signature holds a valid ed25519 signature by the asset's
+// creator. The signature is base64 encoded and covers the JSON encoding of the
+// metadata, as returned by JSON, with the signature property removed. An error is
+// returned if the metadata has no signature or if it cannot be decoded.
+func (a *ARC69) VerifyCreatorSignature(ctx context.Context, assetID uint64, opts ...Option) (bool, error) {
+	cfg := a.config(opts)
+	if cfg.indexerClient == nil {
+		return false, ErrMissingClient
+	}
+
+	meta, err := a.Fetch(ctx, assetID, opts...)
+	if err != nil {
+		return false, err
+	}
+
+	encoded, ok := meta.Properties[signatureProperty].(string)
+	if !ok {
+		return false, fmt.Errorf("no creator signature found for asset %d", assetID)
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false, fmt.Errorf("unable to decode creator signature: %s", err)
+	}
+
+	var asset models.Asset
+	err = observe(ctx, cfg, "indexer.LookupAssetByID", func(ctx context.Context) (err error) {
+		_, asset, err = cfg.indexerClient.LookupAssetByID(assetID).Do(ctx)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to fetch asset %d: %s", assetID, err)
+	}
+	creator, err := types.DecodeAddress(asset.Params.Creator)
+	if err != nil {
+		return false, fmt.Errorf("invalid creator address: %s", err)
+	}
+
+	unsigned := meta.clone()
+	delete(unsigned.Properties, signatureProperty)
+	data, err := unsigned.JSON()
+	if err != nil {
+		return false, err
+	}
+
+	return ed25519.Verify(ed25519.PublicKey(creator[:]), data, sig), nil
+}
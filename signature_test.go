@@ -0,0 +1,61 @@
+package arc69
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+)
+
+// signMetadata returns a copy of meta carrying the signature of its JSON encoding by account.
+func signMetadata(t *testing.T, account crypto.Account, meta *Metadata) *Metadata {
+	data, err := meta.JSON()
+	if err != nil {
+		t.Fatalf("JSON() failed with error: %s", err)
+	}
+	signed := meta.clone()
+	signed.Properties[signatureProperty] = base64.StdEncoding.EncodeToString(ed25519.Sign(account.PrivateKey, data))
+	return signed
+}
+
+func TestVerifyCreatorSignature(t *testing.T) {
+	idx := newFakeIndexer(t)
+	creator := newTestAsset(idx, 1)
+	idx.addAsset(2, idx.assets[1].Params)
+	idx.addAsset(3, idx.assets[1].Params)
+	idx.addAsset(4, idx.assets[1].Params)
+
+	meta := &Metadata{Standard: "arc69", Description: "signed", Properties: map[string]interface{}{"edition": 1}}
+	idx.addMetadata(t, 1, 10, signMetadata(t, creator, meta))
+	idx.addMetadata(t, 2, 10, signMetadata(t, crypto.GenerateAccount(), meta))
+	tampered := signMetadata(t, creator, meta)
+	tampered.Description = "tampered"
+	idx.addMetadata(t, 3, 10, tampered)
+	idx.addMetadata(t, 4, 10, meta)
+	a := New(nil, idx.client(t))
+
+	tests := []struct {
+		assetID uint64
+		want    bool
+	}{
+		{1, true},
+		{2, false},
+		{3, false},
+	}
+	for _, test := range tests {
+		got, err := a.VerifyCreatorSignature(context.Background(), test.assetID)
+		if err != nil {
+			t.Errorf("VerifyCreatorSignature(%d) failed with error: %s, want success", test.assetID, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("VerifyCreatorSignature(%d) = %t, want %t", test.assetID, got, test.want)
+		}
+	}
+
+	if _, err := a.VerifyCreatorSignature(context.Background(), 4); err == nil {
+		t.Errorf("VerifyCreatorSignature() of unsigned metadata succeeded, want error")
+	}
+}
@@ -0,0 +1,240 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+// maxGroupSize is the maximum number of transactions in an atomic group.
+const maxGroupSize = 16
+
+// EstimateUpdateFee returns the fee, in microAlgos, that the network would charge
+// for the acfg transaction updating the metadata of the given asset to meta. The
+// fee is the estimated transaction size times the suggested fee per byte, floored
+// at the network's minimum fee.
+func (a *ARC69) EstimateUpdateFee(ctx context.Context, sender string, assetID uint64, meta *Metadata, opts ...Option) (uint64, error) {
+	txn, err := a.BuildUpdate(ctx, sender, assetID, meta, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(txn.Fee), nil
+}
+
+// MinViableFee returns the lowest fee, in microAlgos, that the network currently
+// accepts for a transaction of sizeBytes bytes: the suggested fee per byte times
+// the size, floored at the network's minimum fee. Bulk updaters can use it to set
+// a flat fee without building each transaction first.
+func (a *ARC69) MinViableFee(ctx context.Context, sizeBytes int, opts ...Option) (uint64, error) {
+	if sizeBytes < 0 {
+		return 0, fmt.Errorf("invalid transaction size: %d", sizeBytes)
+	}
+
+	cfg := a.config(opts)
+	if cfg.algodClient == nil {
+		return 0, ErrMissingClient
+	}
+
+	var txParams types.SuggestedParams
+	err := observe(ctx, cfg, "algod.SuggestedParams", func(ctx context.Context) (err error) {
+		txParams, err = cfg.algodClient.SuggestedParams().Do(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting suggested tx params: %s", err)
+	}
+
+	minFee := txParams.MinFee
+	if minFee == 0 {
+		minFee = future.MinTxnFee
+	}
+
+	fee := uint64(sizeBytes) * uint64(txParams.Fee)
+	if fee < minFee {
+		fee = minFee
+	}
+	return fee, nil
+}
+
+// BuildUpdateGroup builds one unsigned acfg transaction per asset in updates and
+// assigns them a shared group ID, so that once signed by sender and submitted
+// together all of the metadata updates are applied atomically. Transactions are
+// ordered by asset ID. At most 16 assets can be updated in a group.
+func (a *ARC69) BuildUpdateGroup(ctx context.Context, sender string, updates map[uint64]*Metadata, opts ...Option) ([]types.Transaction, error) {
+	if len(updates) == 0 {
+		return nil, fmt.Errorf("no updates provided")
+	}
+	if len(updates) > maxGroupSize {
+		return nil, fmt.Errorf("%d updates exceed the maximum group size of %d", len(updates), maxGroupSize)
+	}
+
+	assetIDs := make([]uint64, 0, len(updates))
+	for id := range updates {
+		assetIDs = append(assetIDs, id)
+	}
+	sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
+
+	cfg := a.config(opts)
+	txns := make([]types.Transaction, 0, len(assetIDs))
+	for _, id := range assetIDs {
+		txn, err := a.buildUpdate(ctx, cfg, sender, id, updates[id])
+		if err != nil {
+			return nil, fmt.Errorf("unable to build update for asset %d: %s", id, err)
+		}
+		txns = append(txns, txn)
+	}
+
+	gid, err := crypto.ComputeGroupID(txns)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute group ID: %s", err)
+	}
+	for i := range txns {
+		txns[i].Group = gid
+	}
+
+	return txns, nil
+}
+
+// UpdateInGroup updates the metadata of an asset like Update, atomically with the
+// other transactions of a group. The acfg transaction is appended to others, the
+// group ID is assigned to all of them, and the acfg transaction is signed by
+// account. Since signatures cover the group ID, others cannot be signed in
+// advance: signOthers is called with others, in order and with their group ID
+// set, and must return their signed encodings for the whole group to be
+// submitted. At most 15 other transactions can be grouped with the update.
+func (a *ARC69) UpdateInGroup(ctx context.Context, account crypto.Account, assetID uint64, meta *Metadata, others []types.Transaction, signOthers func(txns []types.Transaction) ([][]byte, error), opts ...Option) error {
+	if len(others)+1 > maxGroupSize {
+		return fmt.Errorf("%d transactions exceed the maximum group size of %d", len(others)+1, maxGroupSize)
+	}
+
+	cfg := a.config(opts)
+	txn, err := a.buildUpdate(ctx, cfg, account.Address.String(), assetID, meta)
+	if err != nil {
+		return err
+	}
+
+	txns := append(append([]types.Transaction(nil), others...), txn)
+	gid, err := crypto.ComputeGroupID(txns)
+	if err != nil {
+		return fmt.Errorf("unable to compute group ID: %s", err)
+	}
+	for i := range txns {
+		txns[i].Group = gid
+	}
+
+	signedOthers, err := signOthers(txns[:len(others)])
+	if err != nil {
+		return fmt.Errorf("failed to sign grouped transactions: %s", err)
+	}
+	if len(signedOthers) != len(others) {
+		return fmt.Errorf("got %d signed grouped transactions, want %d", len(signedOthers), len(others))
+	}
+
+	txID, signedTxn, err := crypto.SignTransaction(account.PrivateKey, txns[len(others)])
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %s", err)
+	}
+
+	var group []byte
+	for _, signed := range signedOthers {
+		group = append(group, signed...)
+	}
+	if _, err := a.submit(ctx, cfg, txID, append(group, signedTxn...)); err != nil {
+		return err
+	}
+
+	a.InvalidateCache(assetID)
+	return nil
+}
+
+// UpdateError is returned by UpdateMany and holds the error of each asset whose
+// update failed.
+type UpdateError struct {
+	Failures map[uint64]error
+}
+
+func (e *UpdateError) Error() string {
+	ids := make([]uint64, 0, len(e.Failures))
+	for id := range e.Failures {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	msgs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		msgs = append(msgs, fmt.Sprintf("asset %d: %s", id, e.Failures[id]))
+	}
+	return fmt.Sprintf("failed to update %d assets: %s", len(ids), strings.Join(msgs, "; "))
+}
+
+// UpdateMany updates the metadata of each asset in updates like Update, in order
+// of asset ID and up to WithConcurrency updates at a time. progress, when not nil,
+// is called once per asset as its update completes, one call at a time. Failed
+// updates do not stop the others, and once ctx is done no further updates are
+// submitted. An *UpdateError listing every asset that was not updated is returned
+// if any failed or was skipped.
+func (a *ARC69) UpdateMany(ctx context.Context, account crypto.Account, updates map[uint64]*Metadata, progress func(assetID uint64, err error), opts ...Option) error {
+	assetIDs := make([]uint64, 0, len(updates))
+	for id := range updates {
+		assetIDs = append(assetIDs, id)
+	}
+	sort.Slice(assetIDs, func(i, j int) bool { return assetIDs[i] < assetIDs[j] })
+
+	workers := a.config(opts).concurrency
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		failures = make(map[uint64]error)
+		ids      = make(chan uint64)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				err := a.Update(ctx, account, id, updates[id], opts...)
+				mu.Lock()
+				if err != nil {
+					failures[id] = err
+				}
+				if progress != nil {
+					progress(id, err)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i, id := range assetIDs {
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			mu.Lock()
+			for _, skipped := range assetIDs[i:] {
+				failures[skipped] = ctx.Err()
+			}
+			mu.Unlock()
+			break feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+
+	if len(failures) != 0 {
+		return &UpdateError{Failures: failures}
+	}
+	return nil
+}
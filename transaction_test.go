@@ -0,0 +1,393 @@
+package arc69
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/crypto"
+	"github.com/algorand/go-algorand-sdk/future"
+	"github.com/algorand/go-algorand-sdk/transaction"
+	"github.com/algorand/go-algorand-sdk/types"
+)
+
+func TestEstimateUpdateFee(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	sender := account.Address.String()
+	meta := &Metadata{Standard: "arc69", Description: "a description long enough to matter"}
+	note, err := meta.JSON()
+	if err != nil {
+		t.Fatalf("JSON() failed with error: %s", err)
+	}
+
+	tests := []struct {
+		feePerByte, minFee uint64
+		want               uint64
+	}{
+		{0, 2000, 2000},
+		{10, 1000, 4770},
+	}
+	for _, test := range tests {
+		algod.mu.Lock()
+		algod.params.Fee = test.feePerByte
+		algod.params.MinFee = test.minFee
+		algod.mu.Unlock()
+
+		got, err := a.EstimateUpdateFee(context.Background(), sender, 1, meta)
+		if err != nil {
+			t.Fatalf("EstimateUpdateFee() failed with error: %s, want success", err)
+		}
+		if got != test.want {
+			t.Errorf("EstimateUpdateFee() with fee per byte %d and min fee %d = %d, want %d", test.feePerByte, test.minFee, got, test.want)
+		}
+
+		// The fee is the size the SDK estimates for the transaction it builds,
+		// times the fee per byte, floored at the min fee.
+		params, err := algod.client(t).SuggestedParams().Do(context.Background())
+		if err != nil {
+			t.Fatalf("SuggestedParams() failed with error: %s", err)
+		}
+		txn, err := future.MakeAssetConfigTxn(sender, note, params, 1, sender, sender, sender, sender, true)
+		if err != nil {
+			t.Fatalf("MakeAssetConfigTxn() failed with error: %s", err)
+		}
+		size, err := transaction.EstimateSize(txn)
+		if err != nil {
+			t.Fatalf("EstimateSize() failed with error: %s", err)
+		}
+		want := size * test.feePerByte
+		if want < test.minFee {
+			want = test.minFee
+		}
+		if got != want {
+			t.Errorf("EstimateUpdateFee() with fee per byte %d and min fee %d = %d, want %d for %d bytes", test.feePerByte, test.minFee, got, want, size)
+		}
+	}
+}
+
+func TestMinViableFee(t *testing.T) {
+	algod := newFakeAlgod(t, nil)
+	a := New(algod.client(t), nil)
+
+	tests := []struct {
+		feePerByte, minFee uint64
+		size               int
+		want               uint64
+	}{
+		{10, 1000, 50, 1000},
+		{10, 1000, 250, 2500},
+		{0, 0, 250, 1000},
+	}
+	for _, test := range tests {
+		algod.mu.Lock()
+		algod.params.Fee = test.feePerByte
+		algod.params.MinFee = test.minFee
+		algod.mu.Unlock()
+
+		got, err := a.MinViableFee(context.Background(), test.size)
+		if err != nil {
+			t.Fatalf("MinViableFee(%d) failed with error: %s, want success", test.size, err)
+		}
+		if got != test.want {
+			t.Errorf("MinViableFee(%d) with fee per byte %d and min fee %d = %d, want %d", test.size, test.feePerByte, test.minFee, got, test.want)
+		}
+	}
+
+	if _, err := NewOffline().MinViableFee(context.Background(), 100); err != ErrMissingClient {
+		t.Errorf("MinViableFee() without algod = %v, want %v", err, ErrMissingClient)
+	}
+}
+
+func TestBuildUpdateGroup(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	addr := account.Address.String()
+	updates := make(map[uint64]*Metadata)
+	for id := uint64(1); id <= 17; id++ {
+		idx.addAsset(id, idx.assets[1].Params)
+		updates[id] = &Metadata{Standard: "arc69"}
+	}
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	txns, err := a.BuildUpdateGroup(context.Background(), addr, map[uint64]*Metadata{3: updates[3], 1: updates[1], 2: updates[2]})
+	if err != nil {
+		t.Fatalf("BuildUpdateGroup() failed with error: %s, want success", err)
+	}
+	if len(txns) != 3 {
+		t.Fatalf("BuildUpdateGroup() returned %d transactions, want 3", len(txns))
+	}
+
+	ungrouped := make([]types.Transaction, len(txns))
+	for i, txn := range txns {
+		if want := types.AssetIndex(i + 1); txn.ConfigAsset != want {
+			t.Errorf("transaction %d configures asset %d, want %d", i, txn.ConfigAsset, want)
+		}
+		ungrouped[i] = txn
+		ungrouped[i].Group = types.Digest{}
+	}
+	wantGID, err := crypto.ComputeGroupID(ungrouped)
+	if err != nil {
+		t.Fatalf("ComputeGroupID() failed with error: %s", err)
+	}
+	for i, txn := range txns {
+		if txn.Group != wantGID {
+			t.Errorf("transaction %d has group ID %x, want %x", i, txn.Group, wantGID)
+		}
+	}
+
+	if _, err := a.BuildUpdateGroup(context.Background(), addr, updates); err == nil {
+		t.Errorf("BuildUpdateGroup() with 17 updates succeeded, want error")
+	}
+}
+
+func TestBuildUpdateRekeyTo(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	rekeyTo := crypto.GenerateAccount().Address
+	meta := &Metadata{Standard: "arc69"}
+
+	txn, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithRekeyTo(rekeyTo.String()))
+	if err != nil {
+		t.Fatalf("BuildUpdate(WithRekeyTo()) failed with error: %s, want success", err)
+	}
+	if txn.RekeyTo != rekeyTo {
+		t.Errorf("BuildUpdate(WithRekeyTo()) has rekey-to %s, want %s", txn.RekeyTo, rekeyTo)
+	}
+
+	if _, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithRekeyTo("not-an-address")); err == nil {
+		t.Errorf("BuildUpdate(WithRekeyTo(invalid)) succeeded, want error")
+	}
+}
+
+func TestUpdateWithSigner(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	meta := &Metadata{Standard: "arc69", Description: "signed elsewhere"}
+
+	want, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta)
+	if err != nil {
+		t.Fatalf("BuildUpdate() failed with error: %s, want success", err)
+	}
+
+	var signed []byte
+	sign := func(txn types.Transaction) ([]byte, error) {
+		if !reflect.DeepEqual(txn, want) {
+			t.Errorf("sign() called with %+v, want %+v", txn, want)
+		}
+		_, stxn, err := crypto.SignTransaction(account.PrivateKey, txn)
+		signed = stxn
+		return stxn, err
+	}
+
+	txID, err := a.UpdateWithSigner(context.Background(), account.Address.String(), 1, meta, sign)
+	if err != nil {
+		t.Fatalf("UpdateWithSigner() failed with error: %s, want success", err)
+	}
+	if wantID := crypto.TransactionIDString(want); txID != wantID {
+		t.Errorf("UpdateWithSigner() = %s, want %s", txID, wantID)
+	}
+
+	algod.mu.Lock()
+	sent := algod.rawSent
+	algod.mu.Unlock()
+	if len(sent) != 1 || !bytes.Equal(sent[0], signed) {
+		t.Errorf("UpdateWithSigner() submitted %x, want %x", sent, signed)
+	}
+}
+
+func TestUpdateImmutableAsset(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	idx.addAsset(2, models.AssetParams{Creator: account.Address.String()})
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	meta := &Metadata{Standard: "arc69"}
+
+	if err := a.Update(context.Background(), account, 2, meta); err != ErrAssetImmutable {
+		t.Errorf("Update() of locked asset = %v, want %v", err, ErrAssetImmutable)
+	}
+	if len(algod.sentTxns()) != 0 {
+		t.Errorf("Update() of locked asset submitted %d transactions, want none", len(algod.sentTxns()))
+	}
+	if err := a.Update(context.Background(), account, 1, meta); err != nil {
+		t.Errorf("Update() of mutable asset failed with error: %s, want success", err)
+	}
+
+	for id, want := range map[uint64]bool{1: true, 2: false} {
+		got, err := a.IsMutable(context.Background(), id)
+		if err != nil {
+			t.Fatalf("IsMutable(%d) failed with error: %s, want success", id, err)
+		}
+		if got != want {
+			t.Errorf("IsMutable(%d) = %t, want %t", id, got, want)
+		}
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	newTestAsset(idx, 2)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	updates := map[uint64]*Metadata{
+		1: {Standard: "arc69", Description: "one"},
+		2: {Standard: "arc69", Description: "two"},
+		3: {Standard: "arc69", Description: "missing asset"},
+	}
+
+	reported := make(map[uint64]error)
+	err := a.UpdateMany(context.Background(), account, updates, func(assetID uint64, err error) {
+		reported[assetID] = err
+	}, WithConcurrency(2))
+
+	if len(reported) != 3 {
+		t.Fatalf("UpdateMany() reported progress for %d assets, want 3", len(reported))
+	}
+	if reported[1] != nil || reported[2] != nil || reported[3] == nil {
+		t.Errorf("UpdateMany() reported %v, want a failure for asset 3 only", reported)
+	}
+
+	updateErr, ok := err.(*UpdateError)
+	if !ok {
+		t.Fatalf("UpdateMany() = %v, want *UpdateError", err)
+	}
+	if len(updateErr.Failures) != 1 || updateErr.Failures[3] == nil {
+		t.Errorf("UpdateMany() failures = %v, want asset 3", updateErr.Failures)
+	}
+	if len(algod.sentTxns()) != 2 {
+		t.Errorf("UpdateMany() submitted %d transactions, want 2", len(algod.sentTxns()))
+	}
+}
+
+func TestBuildUpdateValidityWindow(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	meta := &Metadata{Standard: "arc69"}
+
+	txn, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithValidityWindow(10))
+	if err != nil {
+		t.Fatalf("BuildUpdate(WithValidityWindow(10)) failed with error: %s, want success", err)
+	}
+	if got := txn.LastValid - txn.FirstValid; got != 10 {
+		t.Errorf("BuildUpdate(WithValidityWindow(10)) is valid for %d rounds, want 10", got)
+	}
+
+	if _, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithValidityWindow(1001)); err == nil {
+		t.Errorf("BuildUpdate(WithValidityWindow(1001)) succeeded, want error")
+	}
+}
+
+func TestBuildUpdateRoleOverrides(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	meta := &Metadata{Standard: "arc69"}
+	newManager := crypto.GenerateAccount().Address
+
+	txn, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithManager(newManager.String()), WithClawback(""))
+	if err != nil {
+		t.Fatalf("BuildUpdate() with role overrides failed with error: %s, want success", err)
+	}
+	params := txn.AssetParams
+	if params.Manager != newManager || params.Clawback != (types.Address{}) {
+		t.Errorf("BuildUpdate() manager = %s, clawback = %s, want %s and cleared", params.Manager, params.Clawback, newManager)
+	}
+	if params.Reserve != account.Address || params.Freeze != account.Address {
+		t.Errorf("BuildUpdate() reserve = %s, freeze = %s, want both preserved as %s", params.Reserve, params.Freeze, account.Address)
+	}
+
+	if _, err := a.BuildUpdate(context.Background(), account.Address.String(), 1, meta, WithFreeze("not-an-address")); err == nil {
+		t.Errorf("BuildUpdate(WithFreeze(invalid)) succeeded, want error")
+	}
+}
+
+func TestUpdateInGroup(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+	payer := crypto.GenerateAccount()
+
+	params := types.SuggestedParams{Fee: 1000, FlatFee: true, FirstRoundValid: 100, LastRoundValid: 1100, GenesisHash: make([]byte, 32), GenesisID: "testnet-v1.0"}
+	payment, err := future.MakePaymentTxn(payer.Address.String(), account.Address.String(), 1000, nil, "", params)
+	if err != nil {
+		t.Fatalf("MakePaymentTxn() failed with error: %s", err)
+	}
+	signOthers := func(txns []types.Transaction) ([][]byte, error) {
+		_, signed, err := crypto.SignTransaction(payer.PrivateKey, txns[0])
+		return [][]byte{signed}, err
+	}
+
+	meta := &Metadata{Standard: "arc69", Description: "paid"}
+	if err := a.UpdateInGroup(context.Background(), account, 1, meta, []types.Transaction{payment}, signOthers); err != nil {
+		t.Fatalf("UpdateInGroup() failed with error: %s, want success", err)
+	}
+
+	sent := algod.sentTxns()
+	if len(sent) != 2 || len(algod.rawSent) != 1 {
+		t.Fatalf("UpdateInGroup() submitted %d transactions in %d requests, want 2 in 1", len(sent), len(algod.rawSent))
+	}
+	if sent[0].Txn.Type != types.PaymentTx || sent[1].Txn.Type != types.AssetConfigTx {
+		t.Errorf("UpdateInGroup() submitted %s and %s transactions, want pay then acfg", sent[0].Txn.Type, sent[1].Txn.Type)
+	}
+	if sent[0].Txn.Group == (types.Digest{}) || sent[0].Txn.Group != sent[1].Txn.Group {
+		t.Errorf("UpdateInGroup() group IDs = %x and %x, want a shared group ID", sent[0].Txn.Group, sent[1].Txn.Group)
+	}
+
+	got, err := a.Fetch(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+	if !got.Equal(meta) {
+		t.Errorf("Fetch() = %+v, want %+v", got, meta)
+	}
+
+	others := make([]types.Transaction, maxGroupSize)
+	if err := a.UpdateInGroup(context.Background(), account, 1, meta, others, signOthers); err == nil {
+		t.Errorf("UpdateInGroup() with %d other transactions succeeded, want error", len(others))
+	}
+}
+
+func TestUpdateIfUnchanged(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	algod := newFakeAlgod(t, idx)
+	a := New(algod.client(t), idx.client(t))
+
+	versions, err := a.FetchHistory(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("FetchHistory() failed with error: %s, want success", err)
+	}
+	seen := versions[0].Round
+
+	meta := &Metadata{Standard: "arc69", Description: "v2"}
+	if err := a.UpdateIfUnchanged(context.Background(), account, 1, meta, seen); err != nil {
+		t.Fatalf("UpdateIfUnchanged() failed with error: %s, want success", err)
+	}
+	if got, err := a.Fetch(context.Background(), 1); err != nil || !got.Equal(meta) {
+		t.Errorf("Fetch() = %+v, %v, want %+v", got, err, meta)
+	}
+
+	if err := a.UpdateIfUnchanged(context.Background(), account, 1, &Metadata{Standard: "arc69", Description: "stale"}, seen); err != ErrConflict {
+		t.Errorf("UpdateIfUnchanged() after a newer update = %v, want %v", err, ErrConflict)
+	}
+	if len(algod.sentTxns()) != 1 {
+		t.Errorf("UpdateIfUnchanged() submitted %d transactions, want 1", len(algod.sentTxns()))
+	}
+}
@@ -0,0 +1,193 @@
+package arc69
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// defaultAllowedSchemes are the URL schemes accepted by Validate by default.
+var defaultAllowedSchemes = []string{"http", "https", "ipfs"}
+
+// ValidationError is returned by Validate and lists every violation found.
+type ValidationError struct {
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid metadata: " + strings.Join(e.Violations, "; ")
+}
+
+// ValidationOption configures the checks made by Validate.
+type ValidationOption func(*validationOptions)
+
+type validationOptions struct {
+	allowedSchemes     []string
+	rejectControlChars bool
+}
+
+// WithAllowedSchemes sets the URL schemes accepted in MediaURL and ExternalURL.
+// The default is http, https and ipfs, which keeps javascript: and data: URLs
+// from reaching naive viewers.
+func WithAllowedSchemes(schemes []string) ValidationOption {
+	return func(o *validationOptions) {
+		o.allowedSchemes = schemes
+	}
+}
+
+// WithRejectControlChars flags control characters other than tab, newline and
+// carriage return in the description, the URLs and the attribute values, which
+// break rendering and logs once the note is decoded.
+func WithRejectControlChars() ValidationOption {
+	return func(o *validationOptions) {
+		o.rejectControlChars = true
+	}
+}
+
+// Validate checks that m is valid ARC69 metadata and complies with the validation
+// policy set by opts. A *ValidationError listing each violation, prefixed by the
+// offending field, is returned if it does not.
+func (m *Metadata) Validate(opts ...ValidationOption) error {
+	cfg := validationOptions{allowedSchemes: defaultAllowedSchemes}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	var violations []string
+	if !m.IsValid() {
+		violations = append(violations, fmt.Sprintf("standard: must be %q, got %q", "arc69", m.Standard))
+	}
+	violations = append(violations, checkScheme("media_url", m.MediaURL, cfg.allowedSchemes)...)
+	violations = append(violations, checkScheme("external_url", m.ExternalURL, cfg.allowedSchemes)...)
+	violations = append(violations, checkProperties("properties", reflect.ValueOf(m.Properties))...)
+	if cfg.rejectControlChars {
+		violations = append(violations, checkControlChars("description", m.Description)...)
+		violations = append(violations, checkControlChars("external_url", m.ExternalURL)...)
+		violations = append(violations, checkControlChars("media_url", m.MediaURL)...)
+		for i, attr := range m.Attributes {
+			violations = append(violations, checkControlChars(fmt.Sprintf("attributes[%d].value", i), attr.ValueString())...)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// ParseMetadata reads ARC69 metadata from r, such as a local file, without any
+// network access and validates it with opts. Like notes, the input may hold plain,
+// base64-encoded or gzip-compressed JSON. If the metadata parses but fails
+// validation, it is returned along with the *ValidationError, so callers only
+// interested in parsing can ignore that error.
+func ParseMetadata(r io.Reader, opts ...ValidationOption) (*Metadata, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read metadata: %s", err)
+	}
+	return ParseMetadataBytes(data, opts...)
+}
+
+// ParseMetadataBytes is like ParseMetadata for metadata held in memory.
+func ParseMetadataBytes(b []byte, opts ...ValidationOption) (*Metadata, error) {
+	meta, err := parseNote(b, &options{})
+	if err != nil {
+		return nil, err
+	}
+	return meta, meta.Validate(opts...)
+}
+
+// ValidateBatch runs Validate on each of the given metadata and returns the
+// violations found keyed by asset ID. Metadata that passes validation is omitted
+// from the report. Violations are listed in the order Validate reports them.
+func ValidateBatch(metas map[uint64]*Metadata, opts ...ValidationOption) map[uint64][]string {
+	report := make(map[uint64][]string)
+	for id, meta := range metas {
+		if meta == nil {
+			report[id] = []string{"metadata is missing"}
+			continue
+		}
+
+		err := meta.Validate(opts...)
+		if verr, ok := err.(*ValidationError); ok {
+			report[id] = verr.Violations
+		} else if err != nil {
+			report[id] = []string{err.Error()}
+		}
+	}
+	return report
+}
+
+// Helper function that checks that a property value is a string, number, bool or
+// null, or a map keyed by strings or a slice of such values. Map keys containing
+// the "." path delimiter are flagged since Property cannot reach them.
+func checkProperties(path string, v reflect.Value) []string {
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid, reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return []string{fmt.Sprintf("%s: unsupported value type %s", path, v.Type())}
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		var violations []string
+		for _, k := range keys {
+			if strings.Contains(k.String(), ".") {
+				violations = append(violations, fmt.Sprintf("%s: key %q contains \".\" and cannot be reached by Property", path, k.String()))
+			}
+			violations = append(violations, checkProperties(path+"."+k.String(), v.MapIndex(k))...)
+		}
+		return violations
+	case reflect.Slice, reflect.Array:
+		var violations []string
+		for i := 0; i < v.Len(); i++ {
+			violations = append(violations, checkProperties(fmt.Sprintf("%s[%d]", path, i), v.Index(i))...)
+		}
+		return violations
+	default:
+		return []string{fmt.Sprintf("%s: unsupported value type %s", path, v.Type())}
+	}
+}
+
+// Helper function that checks that a URL field uses one of the allowed schemes.
+func checkScheme(field, rawURL string, allowed []string) []string {
+	if rawURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: invalid URL: %s", field, err)}
+	}
+
+	for _, scheme := range allowed {
+		if strings.EqualFold(u.Scheme, scheme) {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("%s: scheme %q is not allowed", field, u.Scheme)}
+}
+
+// Helper function that flags the first control character in a string field,
+// allowing the usual whitespace.
+func checkControlChars(field, value string) []string {
+	for i, r := range value {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return []string{fmt.Sprintf("%s: control character %U at byte %d", field, r, i)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package arc69
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetadataValidate(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", MediaURL: "ipfs://cid/media.png", ExternalURL: "https://example.com"}
+	if err := meta.Validate(); err != nil {
+		t.Errorf("Validate() failed with error: %s, want success", err)
+	}
+
+	meta = &Metadata{Standard: "arc68", MediaURL: "javascript:alert(1)", ExternalURL: "data:text/html,hi"}
+	err := meta.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+
+	want := []string{
+		`standard: must be "arc69", got "arc68"`,
+		`media_url: scheme "javascript" is not allowed`,
+		`external_url: scheme "data" is not allowed`,
+	}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Validate() violations = %q, want %q", verr.Violations, want)
+	}
+}
+
+func TestMetadataValidateAllowedSchemes(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", MediaURL: "ar://media"}
+	if err := meta.Validate(); err == nil {
+		t.Errorf("Validate() with ar:// media succeeded under default policy, want error")
+	}
+	if err := meta.Validate(WithAllowedSchemes([]string{"ar"})); err != nil {
+		t.Errorf("Validate(WithAllowedSchemes(ar)) failed with error: %s, want success", err)
+	}
+}
+
+func TestMetadataValidateControlChars(t *testing.T) {
+	clean := &Metadata{Standard: "arc69", Description: "line one\nline two\ttabbed", Attributes: []Attribute{{TraitType: "Hat", Value: "Cap"}}}
+	if err := clean.Validate(WithRejectControlChars()); err != nil {
+		t.Errorf("Validate(WithRejectControlChars()) failed with error: %s, want success", err)
+	}
+
+	meta := &Metadata{Standard: "arc69", Description: "bad\x00byte", Attributes: []Attribute{{TraitType: "Hat", Value: "\x1b[31mred"}}}
+	if err := meta.Validate(); err != nil {
+		t.Errorf("Validate() with control characters failed with error: %s, want success by default", err)
+	}
+
+	err := meta.Validate(WithRejectControlChars())
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate(WithRejectControlChars()) = %v, want *ValidationError", err)
+	}
+	want := []string{
+		"description: control character U+0000 at byte 3",
+		"attributes[0].value: control character U+001B at byte 0",
+	}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Validate(WithRejectControlChars()) violations = %q, want %q", verr.Violations, want)
+	}
+}
+
+func TestValidateBatch(t *testing.T) {
+	got := ValidateBatch(map[uint64]*Metadata{
+		1: {Standard: "arc69", MediaURL: "https://example.com/1.png"},
+		2: {Standard: "arc3", MediaURL: "javascript:alert(1)"},
+		3: nil,
+		4: {Standard: "arc69", ExternalURL: "data:text/html,hi"},
+	})
+
+	want := map[uint64][]string{
+		2: {`standard: must be "arc69", got "arc3"`, `media_url: scheme "javascript" is not allowed`},
+		3: {"metadata is missing"},
+		4: {`external_url: scheme "data" is not allowed`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateBatch() = %q, want %q", got, want)
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	meta, err := ParseMetadata(strings.NewReader(`{"standard":"arc69","description":"offline","media_url":"ipfs://cid"}`))
+	if err != nil {
+		t.Fatalf("ParseMetadata() failed with error: %s, want success", err)
+	}
+	if meta.Description != "offline" || meta.MediaURL != "ipfs://cid" {
+		t.Errorf("ParseMetadata() = %+v, want parsed metadata", meta)
+	}
+
+	meta, err = ParseMetadataBytes([]byte(`{"standard":"arc3","media_url":"javascript:alert(1)"}`))
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 2 {
+		t.Errorf("ParseMetadataBytes() of invalid metadata = %v, want *ValidationError with 2 violations", err)
+	}
+	if meta == nil || meta.Standard != "arc3" {
+		t.Errorf("ParseMetadataBytes() of invalid metadata = %+v, want the parsed metadata", meta)
+	}
+
+	if meta, err := ParseMetadataBytes([]byte(`{"standard":`)); err == nil || meta != nil {
+		t.Errorf("ParseMetadataBytes() of malformed JSON = %+v, %v, want nil and error", meta, err)
+	}
+}
+
+func TestMetadataValidatePropertyTypes(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Properties: map[string]interface{}{
+		"name":  "creature",
+		"level": 3,
+		"stats": map[string]interface{}{"power": 9.5, "rare": true, "tags": []interface{}{"a", "b"}},
+		"none":  nil,
+	}}
+	if err := meta.Validate(); err != nil {
+		t.Errorf("Validate() of nested properties failed with error: %s, want success", err)
+	}
+
+	meta.Properties["stats"].(map[string]interface{})["callback"] = func() {}
+	meta.Properties["events"] = []interface{}{"ok", make(chan int)}
+	err := meta.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+
+	want := []string{
+		"properties.events[1]: unsupported value type chan int",
+		"properties.stats.callback: unsupported value type func()",
+	}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Validate() violations = %q, want %q", verr.Violations, want)
+	}
+}
+
+func TestMetadataValidateDottedPropertyKeys(t *testing.T) {
+	meta := &Metadata{Standard: "arc69", Properties: map[string]interface{}{
+		"files": map[string]interface{}{"file.png": "ipfs://cid"},
+	}}
+
+	err := meta.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Validate() = %v, want *ValidationError", err)
+	}
+	want := []string{`properties.files: key "file.png" contains "." and cannot be reached by Property`}
+	if !reflect.DeepEqual(verr.Violations, want) {
+		t.Errorf("Validate() violations = %q, want %q", verr.Violations, want)
+	}
+}
@@ -0,0 +1,114 @@
+package arc69
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Watch polls the ARC69 metadata of an asset every pollInterval and sends it on
+// the returned channel whenever it changes. The current metadata is sent first.
+// Polling bypasses the Fetch cache, and failed polls are retried at the next
+// interval. The channel is closed once ctx is done or the ARC69 object is closed.
+func (a *ARC69) Watch(ctx context.Context, assetID uint64, pollInterval time.Duration, opts ...Option) (<-chan *Metadata, error) {
+	if pollInterval <= 0 {
+		return nil, fmt.Errorf("poll interval must be positive")
+	}
+
+	last, err := a.fetchUncached(ctx, assetID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil, ErrClosed
+	}
+	a.watchers.Add(1)
+	a.mu.Unlock()
+
+	ch := make(chan *Metadata, 1)
+	ch <- last
+
+	go func() {
+		defer a.watchers.Done()
+		defer close(ch)
+
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-a.done:
+				return
+			case <-ticker.C:
+			}
+
+			meta, err := a.fetchUncached(ctx, assetID, opts)
+			if err != nil || meta.Equal(last) {
+				continue
+			}
+			last = meta
+
+			select {
+			case ch <- meta:
+			case <-ctx.Done():
+				return
+			case <-a.done:
+				return
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+// fetchUncached is Fetch without the cache.
+func (a *ARC69) fetchUncached(ctx context.Context, assetID uint64, opts []Option) (*Metadata, error) {
+	_, meta, err := a.metadataNote(ctx, a.config(opts), assetID)
+	return meta, err
+}
+
+// metadataNote returns the note Fetch reads the metadata of an asset from, along
+// with the metadata it holds.
+func (a *ARC69) metadataNote(ctx context.Context, cfg *options, assetID uint64) ([]byte, *Metadata, error) {
+	var meta *Metadata
+	note, err := a.selectNote(ctx, cfg, assetID, func(note []byte) (err error) {
+		meta, err = parseValidNote(note, cfg)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return note, meta, nil
+}
+
+// selectNote returns the latest note of an asset that parse accepts, or only
+// considers the latest note when WithLatestOnly is set. This is how Fetch and the
+// other methods reading the current metadata of an asset pick its note.
+func (a *ARC69) selectNote(ctx context.Context, cfg *options, assetID uint64, parse func(note []byte) error) ([]byte, error) {
+	notes, err := a.latestNotes(ctx, cfg, assetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var latestErr error
+	for i, note := range notes {
+		err := parse(note)
+		if err == nil {
+			return note, nil
+		}
+
+		if i == 0 {
+			latestErr = err
+			if cfg.latestOnly {
+				break
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("latest note of asset %d does not hold ARC69 metadata: %s", assetID, latestErr)
+}
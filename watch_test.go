@@ -0,0 +1,122 @@
+package arc69
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	a := New(nil, idx.client(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := a.Watch(ctx, 1, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch() failed with error: %s, want success", err)
+	}
+
+	if meta := <-ch; meta.Description != "v1" {
+		t.Errorf("Watch() first emitted %q, want \"v1\"", meta.Description)
+	}
+
+	select {
+	case meta := <-ch:
+		t.Errorf("Watch() emitted %+v without a change, want no emission", meta)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	idx.addMetadata(t, 1, 11, &Metadata{Standard: "arc69", Description: "v2"})
+	select {
+	case meta := <-ch:
+		if meta.Description != "v2" {
+			t.Errorf("Watch() emitted %q after change, want \"v2\"", meta.Description)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Watch() did not emit after change")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("Watch() emitted after cancellation, want closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Watch() channel not closed after cancellation")
+	}
+}
+
+func TestClose(t *testing.T) {
+	idx := newFakeIndexer(t)
+	idx.addMetadata(t, 1, 10, &Metadata{Standard: "arc69", Description: "v1"})
+	a := New(nil, idx.client(t), WithCache(10, time.Minute))
+	if _, err := a.Fetch(context.Background(), 1); err != nil {
+		t.Fatalf("Fetch() failed with error: %s, want success", err)
+	}
+
+	before := runtime.NumGoroutine()
+	ch, err := a.Watch(context.Background(), 1, 5*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Watch() failed with error: %s, want success", err)
+	}
+	<-ch
+
+	for i := 0; i < 2; i++ {
+		if err := a.Close(); err != nil {
+			t.Errorf("Close() call %d failed with error: %s, want success", i+1, err)
+		}
+	}
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Errorf("Watch() channel still open after Close()")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Watch() channel not closed after Close()")
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("%d goroutines running after Close(), want at most %d", after, before)
+	}
+
+	if _, err := a.Fetch(context.Background(), 1); err != ErrClosed {
+		t.Errorf("Fetch() after Close() = %v, want %v", err, ErrClosed)
+	}
+	if _, err := a.Watch(context.Background(), 1, time.Millisecond); err != ErrClosed {
+		t.Errorf("Watch() after Close() = %v, want %v", err, ErrClosed)
+	}
+}
+
+// closingObserver closes an ARC69 object when it sees the first call named op.
+type closingObserver struct {
+	a  *ARC69
+	op string
+}
+
+func (o *closingObserver) OnRequest(op string) {
+	if op == o.op {
+		o.a.Close()
+	}
+}
+
+func (o *closingObserver) OnResponse(op string, dur time.Duration, err error) {}
+
+func TestCloseStopsUpdate(t *testing.T) {
+	idx := newFakeIndexer(t)
+	account := newTestAsset(idx, 1)
+	algod := newFakeAlgod(t, idx)
+	algod.unconfirmed = true
+	obs := &closingObserver{op: "algod.PendingTransactionInformation"}
+	a := New(algod.client(t), idx.client(t), WithObserver(obs))
+	obs.a = a
+
+	err := a.Update(context.Background(), account, 1, &Metadata{Standard: "arc69"})
+	if err == nil || !strings.Contains(err.Error(), ErrClosed.Error()) {
+		t.Errorf("Update() closed while waiting for confirmation = %v, want %v", err, ErrClosed)
+	}
+}